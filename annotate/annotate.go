@@ -0,0 +1,579 @@
+// Package annotate 根据已有的 OpenAPI 2.0（Swagger）文档，在对应的 Go 方法上生成接口注释。
+//
+// 用于文档只存在于 Apifox 或手写 Swagger 文件中的旧服务，按以下顺序将文档中的接口与 Go 方法匹配：
+//   - 注释中已有 @url 的方法，按请求方式和路径匹配，只补充缺少的注释
+//   - 方法名与接口的 operationId 相同（忽略大小写、下划线和连字符）
+//   - 根据请求方式和路径推测的方法名，如：GET /pet/{petId} 匹配 GetPet、FindPet、GetPetById 等
+//
+// 已有的注释不会被修改，只在注释块的末尾添加缺少的注释。
+package annotate
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/whaios/apigo/apifox"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/parser"
+	"go/ast"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 推测方法名时，请求方式对应的动词
+var methodVerbs = map[string][]string{
+	parser.MethodGet:    {"get", "find", "query", "list", "fetch", "show", "search"},
+	parser.MethodPost:   {"create", "add", "new", "post", "save", "insert"},
+	parser.MethodPut:    {"update", "edit", "put", "modify", "save"},
+	parser.MethodPatch:  {"patch", "update", "edit", "modify"},
+	parser.MethodDelete: {"delete", "del", "remove"},
+}
+
+// Operation 文档中的接口
+type Operation struct {
+	Method string // 请求方式，小写
+	Path   string
+	*spec.Operation
+}
+
+// Endpoint 如：GET /pet/{petId}
+func (o *Operation) Endpoint() string {
+	return strings.ToUpper(o.Method) + " " + o.Path
+}
+
+// Annotation 匹配到的方法和需要添加的注释
+type Annotation struct {
+	Operation *Operation
+	File      *goscanner.AstFile
+	Func      *ast.FuncDecl
+	MatchedBy string   // 匹配方式：url、operationId、name
+	Lines     []string // 需要添加的注释，不包括开头的 //
+	Skipped   []string // 无法生成的注释及原因
+
+	scanner *goscanner.Scanner
+	offset  int    // 在文件中插入注释的位置
+	text    string // 插入的内容
+}
+
+// Line 方法所在的行号
+func (a *Annotation) Line() int {
+	return a.File.Position(a.Func.Pos()).Line
+}
+
+// Unmatched 没有匹配到方法的接口
+type Unmatched struct {
+	Operation *Operation
+	Reason    string
+}
+
+// goFunc 扫描到的 Go 方法
+type goFunc struct {
+	file    *goscanner.AstFile
+	decl    *ast.FuncDecl
+	url     string // 注释中已有的 @url，如：GET /pet/{petId}
+	matched bool
+}
+
+// Annotate 将文档中的接口与扫描到的 Go 方法匹配，并生成需要添加的注释，不会修改文件。
+func Annotate(doc *spec.Swagger, scanner *goscanner.Scanner) (*Report, error) {
+	if doc.Swagger != "2.0" {
+		return nil, fmt.Errorf("只支持 OpenAPI 2.0（Swagger）格式的文档，OpenAPI 3 文档请先转换为 2.0 格式")
+	}
+
+	funcs := make([]*goFunc, 0)
+	for _, file := range scanner.Files() {
+		for _, decl := range file.File().Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Name.Name != "init" && fd.Name.Name != "main" {
+				funcs = append(funcs, &goFunc{file: file, decl: fd, url: existingUrl(fd)})
+			}
+		}
+	}
+
+	ops := operations(doc)
+	matched := make(map[*Operation]*Annotation)
+	reasons := make(map[*Operation]string)
+	match := func(op *Operation, fn *goFunc, by string) {
+		fn.matched = true
+		matched[op] = &Annotation{Operation: op, File: fn.file, Func: fn.decl, MatchedBy: by, scanner: scanner}
+	}
+	// 依次按 @url、operationId、推测的方法名匹配，前面的方式优先
+	for _, op := range ops {
+		for _, fn := range funcs {
+			if !fn.matched && fn.url == op.Endpoint() {
+				match(op, fn, "url")
+				break
+			}
+		}
+	}
+	for _, op := range ops {
+		if matched[op] != nil || op.ID == "" {
+			continue
+		}
+		id := normalize(op.ID[strings.LastIndex(op.ID, ".")+1:])
+		if fn := findFunc(funcs, map[string]bool{id: true}); fn != nil {
+			match(op, fn, "operationId")
+		}
+	}
+	for _, op := range ops {
+		if matched[op] != nil {
+			continue
+		}
+		candidates := funcsByName(funcs, guessNames(op))
+		switch len(candidates) {
+		case 0:
+			reasons[op] = "没有找到对应的方法"
+		case 1:
+			match(op, candidates[0], "name")
+		default:
+			names := make([]string, 0, len(candidates))
+			for _, fn := range candidates {
+				names = append(names, fn.decl.Name.Name)
+			}
+			reasons[op] = "匹配到多个方法: " + strings.Join(names, ", ")
+		}
+	}
+
+	report := &Report{}
+	for _, op := range ops {
+		if a := matched[op]; a != nil {
+			a.generate(doc)
+			report.Annotations = append(report.Annotations, a)
+		} else {
+			report.Unmatched = append(report.Unmatched, &Unmatched{Operation: op, Reason: reasons[op]})
+		}
+	}
+	report.dedupe()
+	return report, nil
+}
+
+// operations 按路径和请求方式排序的所有接口
+func operations(doc *spec.Swagger) []*Operation {
+	ops := make([]*Operation, 0)
+	if doc.Paths == nil {
+		return ops
+	}
+	paths := make([]string, 0, len(doc.Paths.Paths))
+	for p := range doc.Paths.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		item := doc.Paths.Paths[p]
+		for _, m := range []struct {
+			method string
+			op     *spec.Operation
+		}{
+			{parser.MethodGet, item.Get}, {parser.MethodPost, item.Post}, {parser.MethodPut, item.Put},
+			{parser.MethodPatch, item.Patch}, {parser.MethodDelete, item.Delete},
+			{parser.MethodHead, item.Head}, {parser.MethodOptions, item.Options},
+		} {
+			if m.op != nil {
+				ops = append(ops, &Operation{Method: m.method, Path: p, Operation: m.op})
+			}
+		}
+	}
+	return ops
+}
+
+// existingUrl 方法注释中已有的 @url
+func existingUrl(fd *ast.FuncDecl) string {
+	for _, line := range commentLines(fd.Doc) {
+		if fields := strings.Fields(line); len(fields) == 3 && strings.ToLower(fields[0]) == parser.TagUrl {
+			return strings.ToUpper(fields[1]) + " " + fields[2]
+		}
+	}
+	return ""
+}
+
+// findFunc 查找唯一一个没有匹配过，且方法名在 names 中的方法
+func findFunc(funcs []*goFunc, names map[string]bool) *goFunc {
+	if candidates := funcsByName(funcs, names); len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// funcsByName 没有匹配过，注释中没有 @url，且方法名在 names 中的方法
+func funcsByName(funcs []*goFunc, names map[string]bool) []*goFunc {
+	candidates := make([]*goFunc, 0)
+	for _, fn := range funcs {
+		if !fn.matched && fn.url == "" && names[normalize(fn.decl.Name.Name)] {
+			candidates = append(candidates, fn)
+		}
+	}
+	return candidates
+}
+
+// guessNames 根据请求方式和路径推测的方法名（已规范化）
+//
+//	如：GET /pet/{petId} -> getpet、findpet、getpetbypetid、getpetbyid 等
+//	GET /pet/findByStatus -> findbystatus、getfindbystatus 等
+func guessNames(op *Operation) map[string]bool {
+	var resource, param string
+	for _, seg := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			param = strings.Trim(seg, "{}")
+		} else if seg != "" {
+			resource, param = seg, ""
+		}
+	}
+	resource = normalize(resource)
+	names := make(map[string]bool)
+	// 路径本身是动作时，如：findByStatus
+	for _, verbs := range methodVerbs {
+		for _, verb := range verbs {
+			if strings.HasPrefix(resource, verb) {
+				names[resource] = true
+			}
+		}
+	}
+	for _, verb := range methodVerbs[op.Method] {
+		names[verb+resource] = true
+		names[verb+resource+"s"] = true
+		if param != "" {
+			names[verb+resource+"by"+normalize(param)] = true
+			names[verb+resource+"byid"] = true
+		}
+	}
+	return names
+}
+
+// normalize 转为小写，并移除字母和数字以外的字符
+func normalize(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// commentLines 注释块中每行移除开头 // 后的内容
+func commentLines(doc *ast.CommentGroup) []string {
+	lines := make([]string, 0)
+	if doc == nil {
+		return lines
+	}
+	for _, comment := range doc.List {
+		lines = append(lines, strings.TrimSpace(strings.TrimLeft(comment.Text, "/")))
+	}
+	return lines
+}
+
+// existing 已有的注释，param 使用 "@param 参数类型 参数名"（body 参数没有参数名）作为 key，resp 使用 "@resp 状态码"
+func existing(funcName string, lines []string) map[string]bool {
+	tags := make(map[string]bool)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		tag := strings.ToLower(fields[0])
+		switch {
+		case tag == strings.ToLower(funcName) && len(fields) > 1:
+			tags[parser.TagTitle] = true
+		case tag == parser.TagParam && len(fields) > 2 && strings.HasSuffix(fields[2], "{}"):
+			tags[parser.TagParam+" "+strings.ToLower(fields[1])] = true
+		case tag == parser.TagParam && len(fields) > 2:
+			tags[parser.TagParam+" "+strings.ToLower(fields[1])+" "+fields[2]] = true
+		case tag == parser.TagResp && len(fields) > 1:
+			tags[parser.TagResp+" "+fields[1]] = true
+		case tag == parser.TagSuccess:
+			tags[parser.TagResp+" 200"] = true
+		default:
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+// commonLines 文件中类型上的通用注释，其中的 @folder 和请求头会作用于文件中所有的接口
+func commonLines(file *ast.File) []string {
+	lines := make([]string, 0)
+	for _, decl := range file.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok {
+			lines = append(lines, commentLines(gd.Doc)...)
+		}
+	}
+	return lines
+}
+
+// generate 生成方法上缺少的注释
+func (a *Annotation) generate(doc *spec.Swagger) {
+	op := a.Operation
+	name := a.Func.Name.Name
+	tags := existing(name, commentLines(a.Func.Doc))
+	common := commonLines(a.File.File())
+	for key := range existing("", common) {
+		if key == parser.TagFolder || strings.HasPrefix(key, parser.TagParam+" header ") {
+			tags[key] = true
+		}
+	}
+	commonResp := ""
+	for _, line := range common {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.ToLower(fields[0]) == parser.TagResp {
+			commonResp = fields[len(fields)-1]
+		}
+	}
+	add := func(key, line string) {
+		if !tags[key] {
+			tags[key] = true
+			a.Lines = append(a.Lines, line)
+		}
+	}
+
+	title := op.Summary
+	if title == "" {
+		title = op.ID
+	}
+	if title == "" {
+		title = op.Endpoint()
+	}
+	title = oneLine(title)
+	if a.Func.Doc != nil {
+		add(parser.TagTitle, parser.TagTitle+"\t"+title)
+	}
+
+	// 目录和标签，没有 Apifox 目录时使用第一个标签作为目录
+	opTags := op.Tags
+	folder, _ := op.Extensions.GetString(apifox.XFolder)
+	if folder == "" && len(opTags) > 0 {
+		folder, opTags = strings.NewReplacer(`\`, `\\`, "/", `\/`).Replace(opTags[0]), opTags[1:]
+	}
+	if folder != "" {
+		add(parser.TagFolder, parser.TagFolder+"\t"+folder)
+	}
+	if len(opTags) > 0 {
+		add(parser.TagTag, parser.TagTag+"\t"+strings.Join(opTags, " "))
+	}
+	if status, _ := op.Extensions.GetString(apifox.XStatus); status != "" {
+		if s, err := parser.ParseStatus(status); err == nil {
+			add(parser.TagStatus, parser.TagStatus+"\t"+string(s))
+		} else {
+			a.Skipped = append(a.Skipped, fmt.Sprintf("%s %s: %s", parser.TagStatus, status, err))
+		}
+	}
+	if desc := strings.TrimSpace(op.Description); desc != "" && !tags[parser.TagDesc] {
+		for _, line := range strings.Split(desc, "\n") {
+			a.Lines = append(a.Lines, strings.TrimSpace(parser.TagDesc+"\t"+strings.TrimSpace(line)))
+		}
+		tags[parser.TagDesc] = true
+	}
+	add(parser.TagUrl, parser.TagUrl+"\t"+op.Endpoint())
+
+	// 请求参数
+	formData := false
+	for _, param := range op.Parameters {
+		if param.Ref.String() != "" {
+			p, ok := doc.Parameters[strings.TrimPrefix(param.Ref.String(), "#/parameters/")]
+			if !ok {
+				a.Skipped = append(a.Skipped, fmt.Sprintf("%s %s: 没有找到引用的参数", parser.TagParam, param.Ref.String()))
+				continue
+			}
+			param = p
+		}
+		switch param.In {
+		case parser.ParamTypeBody:
+			typeName, err := a.goType(param.Schema)
+			if err != nil {
+				a.Skipped = append(a.Skipped, fmt.Sprintf("%s body %s: %s", parser.TagParam, param.Name, err))
+				continue
+			}
+			add(parser.TagParam+" body", fmt.Sprintf("%s\tbody %s{}", parser.TagParam, typeName))
+		default:
+			in := param.In
+			if in == "formData" {
+				in, formData = parser.ParamTypeForm, true
+			}
+			tpe := param.Type
+			if tpe == "" {
+				tpe = parser.STRING
+			}
+			example := param.Example
+			if example == nil {
+				example = param.Extensions["x-example"]
+			}
+			if example == nil {
+				example = param.Default
+			}
+			if example == nil {
+				example = ""
+			}
+			add(parser.TagParam+" "+in+" "+param.Name, fmt.Sprintf("%s\t%s %s %s %t %s %s", parser.TagParam, in, param.Name, tpe, param.Required,
+				quote(fmt.Sprint(example)), quote(param.Description)))
+		}
+	}
+	if formData {
+		bodyType := parser.MimeAliasFormUrlencoded
+		for _, consume := range op.Consumes {
+			if consume == parser.BodyTypeFormData {
+				bodyType = parser.MimeAliasFormData
+			}
+		}
+		add(parser.TagBodyType, parser.TagBodyType+"\t"+bodyType)
+	}
+
+	// 响应
+	if op.Responses != nil {
+		codes := make([]int, 0, len(op.Responses.StatusCodeResponses))
+		for code := range op.Responses.StatusCodeResponses {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			resp := op.Responses.StatusCodeResponses[code]
+			if resp.Schema == nil {
+				continue
+			}
+			typeName, err := a.respType(resp.Schema, commonResp)
+			if err != nil {
+				a.Skipped = append(a.Skipped, fmt.Sprintf("%s %d: %s", parser.TagResp, code, err))
+				continue
+			}
+			if commonResp != "" && typeName == commonResp[:strings.Index(commonResp+"{", "{")]+"{}" {
+				// 与通用响应相同
+				continue
+			}
+			respName := oneLine(resp.Description)
+			if respName == "" {
+				respName = http.StatusText(code)
+			}
+			add(parser.TagResp+" "+strconv.Itoa(code), fmt.Sprintf("%s\t%d %s %s", parser.TagResp, code, quote(respName), typeName))
+		}
+	}
+
+	a.position(title)
+}
+
+// position 计算插入注释的位置和内容
+//   - 方法有注释时，添加到注释块的末尾，注释块中还没有 @ 开头的注释时，先添加一个空行
+//   - 方法没有注释时，在方法前添加 “方法名 接口名称” 开头的注释块
+func (a *Annotation) position(title string) {
+	if len(a.Lines) == 0 {
+		return
+	}
+	lines := make([]string, 0, len(a.Lines)+2)
+	if a.Func.Doc == nil {
+		a.offset = a.File.Position(a.Func.Pos()).Offset
+		lines = append(lines, "// "+a.Func.Name.Name+" "+title, "//")
+		for _, line := range a.Lines {
+			lines = append(lines, "// "+line)
+		}
+		a.text = strings.Join(lines, "\n") + "\n"
+		return
+	}
+
+	a.offset = a.File.Position(a.Func.Doc.End()).Offset
+	hasTag := false
+	for _, line := range commentLines(a.Func.Doc) {
+		hasTag = hasTag || strings.HasPrefix(line, "@")
+	}
+	if !hasTag {
+		lines = append(lines, "//")
+	}
+	for _, line := range a.Lines {
+		lines = append(lines, "// "+line)
+	}
+	a.text = "\n" + strings.Join(lines, "\n")
+}
+
+// goType 查找数据结构对应的 Go 类型，只支持命名的数据模型和基础类型
+//
+//	数据模型名称可以是 Pet、model.Pet 或 petshop/model.Pet，先按包名查找，没有找到时从方法所在的包中查找。
+//	由 apigo 生成的文档中没有引用，使用数据结构中记录的类型全名。
+func (a *Annotation) goType(schema *spec.Schema) (string, error) {
+	if schema == nil {
+		return "", fmt.Errorf("没有数据结构")
+	}
+	name := strings.TrimPrefix(schema.Ref.String(), "#/definitions/")
+	if name == "" {
+		name = parser.SchemaGetTypeFullName(schema)
+	}
+	if name != "" {
+		name = path.Base(name)
+		candidates := []string{name}
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			candidates = append(candidates, name[i+1:])
+		}
+		for _, candidate := range candidates {
+			if typeSpec, err := a.scanner.GetType(candidate, a.File); err == nil && typeSpec != nil {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("数据模型 %s 没有找到对应的 Go 类型", name)
+	}
+	if len(schema.Type) == 1 {
+		switch schema.Type[0] {
+		case parser.STRING:
+			return "string", nil
+		case parser.INTEGER:
+			if schema.Format == "int32" {
+				return "int32", nil
+			}
+			return "int64", nil
+		case parser.NUMBER:
+			if schema.Format == "float" {
+				return "float32", nil
+			}
+			return "float64", nil
+		case parser.BOOLEAN:
+			return "bool", nil
+		}
+	}
+	return "", fmt.Errorf("不支持没有定义数据模型的 %s 类型", strings.Join(schema.Type, ","))
+}
+
+// respType 响应的数据类型，支持组合类型，如：comm.HttpCode{data=model.Pet}
+//
+//	文件中的通用响应已经是组合类型的外层（如：comm.HttpCode{data}）时，只使用内层的类型。
+func (a *Annotation) respType(schema *spec.Schema, commonResp string) (string, error) {
+	if schema == nil || len(schema.AllOf) == 0 {
+		typeName, err := a.goType(schema)
+		return typeName + "{}", err
+	}
+	base, err := a.goType(&schema.AllOf[0])
+	if err != nil {
+		return "", err
+	}
+	fields := make([]string, 0)
+	for _, sub := range schema.AllOf[1:] {
+		for _, propName := range sortedProperties(sub.Properties) {
+			prop := sub.Properties[propName]
+			typeName, err := a.goType(&prop)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, propName+"="+typeName)
+		}
+	}
+	if len(fields) == 1 {
+		if kv := strings.SplitN(fields[0], "=", 2); base+"{"+kv[0]+"}" == commonResp {
+			return kv[1] + "{}", nil
+		}
+	}
+	return base + "{" + strings.Join(fields, ",") + "}", nil
+}
+
+// sortedProperties 排序后的属性名
+func sortedProperties(props map[string]spec.Schema) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// quote 转为注释中使用双引号包裹的值，值中的双引号替换为单引号
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(oneLine(s), `"`, `'`) + `"`
+}
+
+// oneLine 将换行替换为空格
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,199 @@
+package annotate
+
+import (
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const handlerGo = `package handler
+
+// Pet 宠物
+type Pet struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+// GetPetById 查询宠物详情
+func GetPetById() {
+}
+
+func AddPet() {
+}
+
+// UpdatePet 修改宠物
+//
+// @url	PUT /pet
+// @param	body Pet{}
+func UpdatePet() {
+}
+
+func ListOrders() {
+}
+
+func QueryOrders() {
+}
+`
+
+const specJson = `{
+  "swagger": "2.0",
+  "paths": {
+    "/pet": {
+      "post": {
+        "summary": "新建宠物",
+        "operationId": "addPet",
+        "tags": ["宠物商城/宠物管理", "internal"],
+        "consumes": ["multipart/form-data"],
+        "parameters": [
+          {"in": "formData", "name": "name", "type": "string", "required": true, "description": "宠物\"名\""},
+          {"in": "formData", "name": "photo", "type": "file"}
+        ],
+        "responses": {"200": {"description": "成功", "schema": {"$ref": "#/definitions/model.Pet"}}}
+      },
+      "put": {
+        "summary": "修改宠物",
+        "parameters": [{"in": "body", "name": "body", "schema": {"$ref": "#/definitions/Pet"}}],
+        "responses": {"200": {"description": "", "schema": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}}}
+      }
+    },
+    "/pet/{petId}": {
+      "get": {
+        "summary": "查询宠物详情",
+        "description": "第一行\n第二行",
+        "x-apifox-folder": "宠物商城/宠物管理",
+        "x-apifox-status": "released",
+        "parameters": [{"in": "path", "name": "petId", "type": "integer", "required": true, "x-example": 1}],
+        "responses": {"200": {"description": "成功", "schema": {"allOf": [{"$ref": "#/definitions/Pet"}, {"properties": {"total": {"type": "integer"}}}]}}}
+      }
+    },
+    "/orders": {
+      "get": {"summary": "订单列表", "responses": {}}
+    },
+    "/store": {
+      "get": {"summary": "商店", "responses": {}}
+    }
+  },
+  "definitions": {"Pet": {"type": "object"}, "model.Pet": {"type": "object"}}
+}`
+
+func TestAnnotate(t *testing.T) {
+	Convey("测试根据文档生成注释", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module legacy\n\ngo 1.19\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "handler.go"), []byte(handlerGo), 0644), ShouldBeNil)
+
+		doc := &spec.Swagger{}
+		So(json.Unmarshal([]byte(specJson), doc), ShouldBeNil)
+		scanner := goscanner.New()
+		So(scanner.Scan(dir), ShouldBeNil)
+
+		report, err := Annotate(doc, scanner)
+		So(err, ShouldBeNil)
+		So(report.Annotations, ShouldHaveLength, 3)
+		So(report.Unmatched, ShouldHaveLength, 2)
+		So(report.Unmatched[0].Operation.Endpoint(), ShouldEqual, "GET /orders")
+		So(report.Unmatched[0].Reason, ShouldEqual, "匹配到多个方法: ListOrders, QueryOrders")
+		So(report.Unmatched[1].Reason, ShouldEqual, "没有找到对应的方法")
+
+		// 按 operationId 匹配，没有注释的方法生成完整的注释块
+		add := report.Annotations[0]
+		So(add.Func.Name.Name, ShouldEqual, "AddPet")
+		So(add.MatchedBy, ShouldEqual, "operationId")
+		So(add.Lines, ShouldResemble, []string{
+			"@folder\t宠物商城\\/宠物管理",
+			"@tag\tinternal",
+			"@url\tPOST /pet",
+			`@param	form name string true "" "宠物'名'"`,
+			`@param	form photo file false "" ""`,
+			"@bodytype\tform-data",
+			`@resp	200 "成功" Pet{}`,
+		})
+
+		// 按 @url 匹配，只补充缺少的注释
+		update := report.Annotations[1]
+		So(update.MatchedBy, ShouldEqual, "url")
+		So(update.Lines, ShouldBeEmpty)
+		So(update.Skipped, ShouldHaveLength, 1)
+
+		// 按推测的方法名匹配
+		get := report.Annotations[2]
+		So(get.Func.Name.Name, ShouldEqual, "GetPetById")
+		So(get.MatchedBy, ShouldEqual, "name")
+		So(get.Lines, ShouldResemble, []string{
+			"@folder\t宠物商城/宠物管理",
+			"@status\treleased",
+			"@desc\t第一行",
+			"@desc\t第二行",
+			"@url\tGET /pet/{petId}",
+			`@param	path petId integer true "1" ""`,
+			`@resp	200 "成功" Pet{total=int64}`,
+		})
+
+		So(report.Write(), ShouldBeNil)
+		src, err := os.ReadFile(filepath.Join(dir, "handler.go"))
+		So(err, ShouldBeNil)
+		So(string(src), ShouldContainSubstring, "// AddPet 新建宠物\n//\n// @folder\t宠物商城\\/宠物管理\n")
+		So(string(src), ShouldContainSubstring, "// GetPetById 查询宠物详情\n//\n// @folder\t宠物商城/宠物管理\n")
+
+		// 生成的注释可以被解析
+		p := parser.NewParser()
+		_, err = p.Scan(dir)
+		So(err, ShouldBeNil)
+		items, err := p.Parse()
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 3)
+		So(items[0].Title, ShouldEqual, "查询宠物详情")
+		So(items[1].Name(), ShouldEqual, `宠物商城\/宠物管理/新建宠物`)
+		So(strings.Join(items[1].Tags, ","), ShouldEqual, "internal")
+	})
+
+	Convey("测试多个接口在同一位置插入注释时只保留第一个", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module legacy\n\ngo 1.19\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "handler.go"), []byte(handlerGo), 0644), ShouldBeNil)
+
+		doc := &spec.Swagger{}
+		So(json.Unmarshal([]byte(specJson), doc), ShouldBeNil)
+		scanner := goscanner.New()
+		So(scanner.Scan(dir), ShouldBeNil)
+		report, err := Annotate(doc, scanner)
+		So(err, ShouldBeNil)
+
+		add := report.Annotations[0]
+		dup := *add
+		dup.Operation = &Operation{Method: parser.MethodPost, Path: "/pets", Operation: add.Operation.Operation}
+		report.Annotations = append(report.Annotations, &dup)
+		report.dedupe()
+		So(report.Annotations, ShouldHaveLength, 3)
+		So(report.Unmatched, ShouldHaveLength, 3)
+		So(report.Unmatched[2].Operation.Endpoint(), ShouldEqual, "POST /pets")
+		So(report.Unmatched[2].Reason, ShouldEqual, "与 POST /pet 匹配到同一个方法 AddPet()")
+
+		So(report.Write(), ShouldBeNil)
+		src, err := os.ReadFile(filepath.Join(dir, "handler.go"))
+		So(err, ShouldBeNil)
+		So(strings.Count(string(src), "// AddPet 新建宠物"), ShouldEqual, 1)
+	})
+
+	Convey("测试不支持的文档版本", t, func() {
+		_, err := Annotate(&spec.Swagger{}, goscanner.New())
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestGuessNames(t *testing.T) {
+	Convey("测试推测方法名", t, func() {
+		names := guessNames(&Operation{Method: parser.MethodGet, Path: "/pet/{petId}"})
+		So(names["getpet"], ShouldBeTrue)
+		So(names["findpetbypetid"], ShouldBeTrue)
+		So(names["pet"], ShouldBeFalse)
+
+		names = guessNames(&Operation{Method: parser.MethodGet, Path: "/pet/findByStatus"})
+		So(names["findbystatus"], ShouldBeTrue)
+	})
+}
@@ -0,0 +1,110 @@
+package annotate
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Report 匹配结果
+type Report struct {
+	Annotations []*Annotation // 匹配到方法的接口
+	Unmatched   []*Unmatched  // 没有匹配到方法的接口
+}
+
+// Changed 需要添加注释的方法个数
+func (r *Report) Changed() int {
+	n := 0
+	for _, a := range r.Annotations {
+		if len(a.Lines) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// dedupe 多个接口在同一文件的同一位置插入注释时（匹配到同一个方法），只保留第一个，其他的作为没有匹配到方法的接口
+func (r *Report) dedupe() {
+	positions := make(map[string]*Annotation)
+	annotations := make([]*Annotation, 0, len(r.Annotations))
+	for _, a := range r.Annotations {
+		if a.text != "" {
+			key := fmt.Sprintf("%s:%d", a.File.AbsPath(), a.offset)
+			if first, ok := positions[key]; ok {
+				r.Unmatched = append(r.Unmatched, &Unmatched{
+					Operation: a.Operation,
+					Reason:    fmt.Sprintf("与 %s 匹配到同一个方法 %s()", first.Operation.Endpoint(), a.Func.Name.Name),
+				})
+				continue
+			}
+			positions[key] = a
+		}
+		annotations = append(annotations, a)
+	}
+	r.Annotations = annotations
+}
+
+// Write 将注释写入 Go 代码文件，同一文件中的注释从后往前插入，避免位置偏移
+func (r *Report) Write() error {
+	files := make(map[string][]*Annotation)
+	paths := make([]string, 0)
+	for _, a := range r.Annotations {
+		if a.text == "" {
+			continue
+		}
+		path := a.File.AbsPath()
+		if _, ok := files[path]; !ok {
+			paths = append(paths, path)
+		}
+		files[path] = append(files[path], a)
+	}
+
+	for _, path := range paths {
+		annotations := files[path]
+		sort.Slice(annotations, func(i, j int) bool {
+			return annotations[i].offset > annotations[j].offset
+		})
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, a := range annotations {
+			if a.offset > len(src) {
+				return fmt.Errorf("文件 %s 已发生变化，请重新执行", path)
+			}
+			src = append(src[:a.offset], append([]byte(a.text), src[a.offset:]...)...)
+		}
+		if err = os.WriteFile(path, src, info.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Text 纯文本格式，列出添加的注释、无法生成的注释和没有匹配到方法的接口
+func (r *Report) Text() string {
+	var sb strings.Builder
+	for _, a := range r.Annotations {
+		sb.WriteString(fmt.Sprintf("%s -> %s:%d %s()（按 %s 匹配）\n", a.Operation.Endpoint(), a.File.Path(), a.Line(), a.Func.Name.Name, a.MatchedBy))
+		for _, line := range a.Lines {
+			sb.WriteString("\t+ // " + line + "\n")
+		}
+		for _, skipped := range a.Skipped {
+			sb.WriteString("\t! " + skipped + "\n")
+		}
+	}
+	if len(r.Unmatched) > 0 {
+		sb.WriteString("\n没有匹配到方法的接口:\n")
+		for _, u := range r.Unmatched {
+			sb.WriteString(fmt.Sprintf("\t%s %s（operationId: %s）%s\n", u.Operation.Endpoint(), u.Operation.Summary, u.Operation.ID, u.Reason))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n匹配到 %d 个接口，其中 %d 个方法需要添加注释，%d 个接口没有匹配到方法",
+		len(r.Annotations), r.Changed(), len(r.Unmatched)))
+	return sb.String()
+}
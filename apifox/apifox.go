@@ -2,28 +2,30 @@
 package apifox
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/levigross/grequests"
+	"github.com/go-openapi/spec"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	BaseUrl = "https://api.apifox.cn" // 开放接口地址
-	Version = "2022-11-16"            // 接口版本号，当前统一填写：2022-11-16
-)
-
-var (
-	ProjectId   string // 项目 ID，打开 Apifox 进入项目里的“项目设置”查看
-	AccessToken string // 身份认证，个人访问令牌，查看[如何获取个人访问令牌](https://www.apifox.cn/help/openapi/)
+	BaseUrlCN     = "https://api.apifox.cn"  // 开放接口地址（国内）
+	BaseUrlGlobal = "https://api.apifox.com" // 开放接口地址（海外）
+	Version       = "2022-11-16"             // 接口版本号，当前统一填写：2022-11-16
+	ExportVersion = "2024-03-28"             // 导出接口的版本号
 
-	// ApiOverwriteMode 匹配到相同接口时的覆盖模式，不传表示忽略。
-	//	枚举值: methodAndPath=覆盖，both=保留两者，merge=智能合并，ignore=不导入
-	ApiOverwriteMode string
-	// SchemaOverwriteMode 匹配到相同数据模型时的覆盖模式，不传表示忽略。
-	//	枚举值: name=覆盖，both=保留两者，merge=智能合并，ignore=不导入
-	SchemaOverwriteMode string
-	// SyncApiFolder 是否同步更新接口所在目录（默认值: false）
-	SyncApiFolder bool
+	DefaultBaseUrl    = BaseUrlCN
+	DefaultTimeout    = 60 * time.Second
+	DefaultMaxRetries = 3
+	DefaultRetryWait  = time.Second
 )
 
 const (
@@ -37,54 +39,252 @@ const (
 	XOrders = "x-apifox-orders" // 属性排序
 )
 
-// PostImportData 导入接口数据
-//   - importFormat 导入数据格式，目前只支持openapi
+// NewClient 创建开放 API 客户端
+//   - projectId 项目 ID，打开 Apifox 进入项目里的“项目设置”查看
+//   - accessToken 身份认证，个人访问令牌，查看[如何获取个人访问令牌](https://www.apifox.cn/help/openapi/)
+func NewClient(projectId, accessToken string) *Client {
+	return &Client{
+		BaseUrl:     DefaultBaseUrl,
+		ProjectId:   projectId,
+		AccessToken: accessToken,
+		Timeout:     DefaultTimeout,
+		MaxRetries:  DefaultMaxRetries,
+		RetryWait:   DefaultRetryWait,
+	}
+}
+
+// Client 开放 API 客户端
+type Client struct {
+	BaseUrl     string // 开放接口地址，海外版使用 BaseUrlGlobal，私有化部署或测试时可以指定其他地址
+	ProjectId   string // 项目 ID
+	AccessToken string // 个人访问令牌
+
+	Proxy      string        // HTTP 代理地址，如：http://127.0.0.1:7890，为空时使用环境变量 HTTP_PROXY、HTTPS_PROXY
+	Timeout    time.Duration // 单次请求的超时时间，0 表示不超时
+	MaxRetries int           // 返回 429 或 5xx 错误、请求发出之前出现连接错误时的最大重试次数
+	RetryWait  time.Duration // 第一次重试前的等待时间，之后每次重试等待时间翻倍
+
+	// HttpClient 不为空时使用该客户端发送请求，忽略 Proxy 和 Timeout
+	HttpClient *http.Client
+}
+
+// ImportOptions 导入接口数据的选项
+type ImportOptions struct {
+	// ApiOverwriteMode 匹配到相同接口时的覆盖模式，不传表示忽略。
+	//	枚举值: methodAndPath=覆盖，both=保留两者，merge=智能合并，ignore=不导入
+	ApiOverwriteMode string
+	// SchemaOverwriteMode 匹配到相同数据模型时的覆盖模式，不传表示忽略。
+	//	枚举值: name=覆盖，both=保留两者，merge=智能合并，ignore=不导入
+	SchemaOverwriteMode string
+	// SyncApiFolder 是否同步更新接口所在目录（默认值: false）
+	SyncApiFolder bool
+}
+
+// StatusError 接口返回了错误的 http 状态码
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Apifox 返回错误, status: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// ImportData 导入接口数据
 //   - jsonData Swagger（OpenAPI） 格式 json 字符串，如 ../example/openapi_data.json
-func PostImportData(jsonData string) (*ImportDataResult, error) {
-	if ProjectId == "" {
+func (c *Client) ImportData(ctx context.Context, jsonData string, opts ImportOptions) (*ImportDataResult, error) {
+	if c.ProjectId == "" {
 		return nil, fmt.Errorf("[项目ID]不能为空")
 	}
-	if AccessToken == "" {
+	if c.AccessToken == "" {
 		return nil, fmt.Errorf("[访问令牌]不能为空")
 	}
 
-	url := fmt.Sprintf("%s/api/v1/projects/%s/import-data", BaseUrl, ProjectId)
-	headers := map[string]string{
-		"X-Apifox-Version": Version,
-		"Authorization":    "Bearer " + AccessToken,
+	data := url.Values{}
+	data.Set("importFormat", OpenApi)
+	data.Set("data", jsonData)
+	data.Set("syncApiFolder", strconv.FormatBool(opts.SyncApiFolder))
+	if opts.ApiOverwriteMode != "" {
+		data.Set("apiOverwriteMode", opts.ApiOverwriteMode)
 	}
-	data := map[string]string{
-		"importFormat":  OpenApi,
-		"data":          jsonData,
-		"syncApiFolder": strconv.FormatBool(SyncApiFolder),
+	if opts.SchemaOverwriteMode != "" {
+		data.Set("schemaOverwriteMode", opts.SchemaOverwriteMode)
 	}
-	{
-		if ApiOverwriteMode != "" {
-			data["apiOverwriteMode"] = ApiOverwriteMode
-		}
-		if SchemaOverwriteMode != "" {
-			data["schemaOverwriteMode"] = SchemaOverwriteMode
-		}
+
+	body, err := c.post(ctx, fmt.Sprintf("/api/v1/projects/%s/import-data", url.PathEscape(c.ProjectId)),
+		"application/x-www-form-urlencoded", data.Encode(), nil)
+	if err != nil {
+		return nil, err
 	}
-	resp, err := grequests.Post(url, &grequests.RequestOptions{
-		Data:    data,
-		Headers: headers,
+
+	result := &ImportDataResult{}
+	if err = json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("解析 Apifox 返回结果出错: %s, body: %s", err, body)
+	}
+	result.Raw = body
+	return result, nil
+}
+
+// ExportOpenApi2 导出项目中当前的所有接口，返回 OpenAPI 2.0（Swagger）文档，包含 Apifox 扩展字段（如：x-apifox-folder）。
+func (c *Client) ExportOpenApi2(ctx context.Context) (*spec.Swagger, error) {
+	if c.ProjectId == "" {
+		return nil, fmt.Errorf("[项目ID]不能为空")
+	}
+	if c.AccessToken == "" {
+		return nil, fmt.Errorf("[访问令牌]不能为空")
+	}
+
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"scope": map[string]interface{}{"type": "ALL"},
+		"options": map[string]interface{}{
+			"includeApifoxExtensionProperties": true,
+			"addFoldersToTags":                 false,
+		},
+		"oasVersion":   "2.0",
+		"exportFormat": "JSON",
 	})
 	if err != nil {
 		return nil, err
 	}
+	header := http.Header{}
+	header.Set("X-Apifox-Api-Version", ExportVersion)
+	body, err := c.post(ctx, fmt.Sprintf("/v1/projects/%s/export-openapi", url.PathEscape(c.ProjectId)),
+		"application/json", string(reqBody), header)
+	if err != nil {
+		return nil, err
+	}
 
-	result := &ImportDataResult{}
-	return result, resp.JSON(result)
+	doc := &spec.Swagger{}
+	if err = json.Unmarshal(body, doc); err != nil {
+		return nil, fmt.Errorf("解析 Apifox 导出的文档出错: %s", err)
+	}
+	return doc, nil
+}
+
+// post 发送请求，返回 429 或 5xx 错误、请求发送之前出现连接错误时按指数退避重试。
+//
+//	请求已经发出后的网络错误（如：等待响应超时）不重试，避免服务端重复导入。
+func (c *Client) post(ctx context.Context, path, contentType, body string, header http.Header) ([]byte, error) {
+	httpClient, err := c.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	wait := c.RetryWait
+	for attempt := 0; ; attempt++ {
+		respBody, retryAfter, err := c.doPost(ctx, httpClient, path, contentType, body, header)
+		if err == nil {
+			return respBody, nil
+		}
+		if attempt >= c.MaxRetries || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (c *Client) doPost(ctx context.Context, httpClient *http.Client, path, contentType, body string, header http.Header) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.BaseUrl, "/")+path, strings.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Apifox-Version", Version)
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	// 记录请求是否已经发出，没有发出时服务端一定没有收到请求
+	var wrote atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { wrote.Store(true) },
+	}))
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if !wrote.Load() {
+			return nil, 0, &connError{err: err}
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryAfter := time.Duration(0)
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(sec) * time.Second
+		}
+		return nil, retryAfter, &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	return respBody, 0, nil
+}
+
+// connError 请求发出之前出现的连接错误，如：连接被拒绝、DNS 解析失败
+type connError struct {
+	err error
+}
+
+func (e *connError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connError) Unwrap() error {
+	return e.err
+}
+
+// retryable 是否需要重试：429、5xx 错误和请求发出之前的连接错误，取消的请求不重试
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch e := err.(type) {
+	case *StatusError:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	case *connError:
+		return true
+	}
+	return false
+}
+
+func (c *Client) httpClient() (*http.Client, error) {
+	if c.HttpClient != nil {
+		return c.HttpClient, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy != "" {
+		proxyUrl, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("代理地址格式错误 %s: %s", c.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	return &http.Client{Transport: transport, Timeout: c.Timeout}, nil
 }
 
 // ImportDataResult 导入接口数据返回结果
 type ImportDataResult struct {
-	Success bool `json:"success"` // 接口状态
-	Data    struct {
+	Success      bool   `json:"success"`      // 接口状态
+	ErrorCode    string `json:"errorCode"`    // 失败时的错误码
+	ErrorMessage string `json:"errorMessage"` // 失败时的错误信息
+	Data         struct {
 		ApiCollection    ImportDataResultCollection `json:"apiCollection"`    // 导入接口情况
 		SchemaCollection ImportDataResultCollection `json:"schemaCollection"` // 导入数据模型情况
 	} `json:"data"` // 导入结果
+
+	Raw json.RawMessage `json:"-"` // Apifox 返回的原始数据
 }
 
 func (p *ImportDataResult) String() string {
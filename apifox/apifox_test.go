@@ -1,38 +1,402 @@
 package apifox_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
 	"github.com/whaios/apigo/apifox"
+	"github.com/whaios/apigo/filter"
 	"github.com/whaios/apigo/parser"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
 )
 
-func ExamplePostImportData() {
-	apifox.ProjectId = "0"
-	apifox.AccessToken = "a"
+func TestClient_ImportData(t *testing.T) {
+	Convey("测试导入接口数据", t, func() {
+		openApiJsonData, err := os.ReadFile(filepath.Join("../example", "openapi_data.json"))
+		So(err, ShouldBeNil)
 
-	openApiJsonData, err := os.ReadFile(filepath.Join("../example", "openapi_data.json"))
-	if err != nil {
-		panic(err)
+		// 模拟 Apifox 开放接口，前两次请求返回 503 错误
+		requests := 0
+		var lastReq *http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			_ = r.ParseForm()
+			lastReq = r
+			if requests <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":{"apiCollection":{"item":{"createCount":1,"updateCount":4}}}}`))
+		}))
+		defer server.Close()
+
+		client := apifox.NewClient("1", "a")
+		client.BaseUrl = server.URL
+		client.RetryWait = time.Millisecond
+		opts := apifox.ImportOptions{ApiOverwriteMode: "methodAndPath", SyncApiFolder: true}
+
+		result, err := client.ImportData(context.Background(), string(openApiJsonData), opts)
+		So(err, ShouldBeNil)
+		So(requests, ShouldEqual, 3)
+		So(lastReq.URL.Path, ShouldEqual, "/api/v1/projects/1/import-data")
+		So(lastReq.Header.Get("Authorization"), ShouldEqual, "Bearer a")
+		So(lastReq.Header.Get("X-Apifox-Version"), ShouldEqual, apifox.Version)
+		So(lastReq.PostForm.Get("data"), ShouldEqual, string(openApiJsonData))
+		So(lastReq.PostForm.Get("apiOverwriteMode"), ShouldEqual, "methodAndPath")
+		So(lastReq.PostForm.Get("syncApiFolder"), ShouldEqual, "true")
+		So(result.Success, ShouldBeTrue)
+		So(result.Data.ApiCollection.Item.CreateCount, ShouldEqual, 1)
+		So(result.Data.ApiCollection.Item.UpdateCount, ShouldEqual, 4)
+
+		// 超过重试次数
+		requests = 0
+		client.MaxRetries = 1
+		_, err = client.ImportData(context.Background(), string(openApiJsonData), opts)
+		So(err, ShouldNotBeNil)
+		So(requests, ShouldEqual, 2)
+	})
+
+	Convey("测试导入失败时返回响应内容", t, func() {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"success":false,"errorMessage":"无效的访问令牌"}`))
+		}))
+		defer server.Close()
+
+		client := apifox.NewClient("1", "a")
+		client.BaseUrl = server.URL
+		_, err := client.ImportData(context.Background(), "{}", apifox.ImportOptions{})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "无效的访问令牌")
+		So(requests, ShouldEqual, 1)
+	})
+
+	Convey("测试取消请求", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := apifox.NewClient("1", "a")
+		client.BaseUrl = server.URL
+		client.RetryWait = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := client.ImportData(ctx, "{}", apifox.ImportOptions{})
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+	})
+
+	Convey("测试请求发出之前的连接错误重试，请求发出之后的网络错误不重试", t, func() {
+		var requests, dials int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) > 1 {
+				// 收到请求后不返回响应，直接断开连接
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				_ = conn.Close()
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true}`))
+		}))
+		defer server.Close()
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		dialContext := transport.DialContext
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if atomic.AddInt32(&dials, 1) <= 2 {
+				return nil, errors.New("connection refused")
+			}
+			return dialContext(ctx, network, addr)
+		}
+
+		client := apifox.NewClient("1", "a")
+		client.BaseUrl = server.URL
+		client.HttpClient = &http.Client{Transport: transport}
+		client.RetryWait = time.Millisecond
+		result, err := client.ImportData(context.Background(), "{}", apifox.ImportOptions{})
+		So(err, ShouldBeNil)
+		So(result.Success, ShouldBeTrue)
+		So(atomic.LoadInt32(&dials), ShouldEqual, 3)
+		So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+
+		// 服务端已经收到请求，可能已经导入，不能重试
+		_, err = client.ImportData(context.Background(), "{}", apifox.ImportOptions{})
+		So(err, ShouldNotBeNil)
+		So(atomic.LoadInt32(&requests), ShouldEqual, 2)
+	})
+}
+
+func TestNewImportReport(t *testing.T) {
+	Convey("测试生成导入报告", t, func() {
+		goParser := parser.NewParser()
+		_, err := goParser.Scan("../example/petshop/pet")
+		So(err, ShouldBeNil)
+		items, err := goParser.Parse()
+		So(err, ShouldBeNil)
+
+		result := &apifox.ImportDataResult{}
+		err = json.Unmarshal([]byte(`{"success":true,"data":{"apiCollection":{
+			"item":{"createCount":3,"errorCount":1,"ignoreCount":1}}}}`), result)
+		So(err, ShouldBeNil)
+
+		report := apifox.NewImportReport(items, result)
+		So(report.Success, ShouldBeTrue)
+		So(report.HasError(), ShouldBeTrue)
+		So(report.Total, ShouldEqual, len(items))
+
+		var table strings.Builder
+		report.Table(&table)
+		lines := strings.Split(table.String(), "\n")
+		So(strings.Fields(lines[1]), ShouldResemble, []string{"接口", "3", "0", "1", "1"})
+	})
+
+	Convey("测试导入失败的报告", t, func() {
+		result := &apifox.ImportDataResult{}
+		err := json.Unmarshal([]byte(`{"success":false,"errorCode":"403012","errorMessage":"没有项目权限"}`), result)
+		So(err, ShouldBeNil)
+
+		report := apifox.NewImportReport(nil, result)
+		So(report.HasError(), ShouldBeTrue)
+		So(report.ErrorMessage, ShouldEqual, "没有项目权限")
+
+		var table strings.Builder
+		report.Table(&table)
+		So(table.String(), ShouldContainSubstring, "403012")
+	})
+}
+
+func TestSplitOpenApi2(t *testing.T) {
+	newOp := func(folder string, ref string) *spec.Operation {
+		op := spec.NewOperation("").RespondsWith(200, spec.NewResponse().WithSchema(spec.RefSchema(ref)))
+		op.AddExtension(apifox.XFolder, folder)
+		return op
 	}
-	result, err := apifox.PostImportData(string(openApiJsonData))
-	if err != nil {
-		panic(err)
+	api := apifox.NewOpenApi2()
+	api.Paths.Paths["/pet"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get:  newOp("宠物商城/宠物管理", "#/definitions/Pet"),
+		Post: newOp("宠物商城/宠物管理", "#/definitions/Pet"),
+	}}
+	api.Paths.Paths["/store"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get: newOp("宠物商城/商店", "#/definitions/Store"),
+	}}
+	api.Paths.Paths["/user"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get: newOp(`用户\/管理/登录`, "#/definitions/User"),
+	}}
+	api.Definitions = spec.Definitions{
+		"Pet":      *spec.RefProperty("#/definitions/Category"),
+		"Category": *spec.StringProperty(),
+		"Store":    *spec.StringProperty(),
+		"User":     *spec.StringProperty(),
 	}
 
-	resultData, err := json.Marshal(result)
-	if err != nil {
-		panic(err)
+	Convey("测试不拆分文档", t, func() {
+		docs := apifox.SplitOpenApi2(api, apifox.SplitOptions{MaxOperations: 10})
+		So(docs, ShouldHaveLength, 1)
+		So(docs[0], ShouldEqual, api)
+	})
+
+	Convey("测试按一级目录拆分文档", t, func() {
+		docs := apifox.SplitOpenApi2(api, apifox.SplitOptions{ByFolder: true})
+		So(docs, ShouldHaveLength, 2)
+		So(docs[0].Paths.Paths, ShouldContainKey, "/pet")
+		So(docs[0].Paths.Paths, ShouldContainKey, "/store")
+		So(docs[0].Definitions, ShouldHaveLength, 3)
+		So(docs[0].Definitions, ShouldContainKey, "Category")
+		So(docs[1].Paths.Paths, ShouldContainKey, "/user")
+		So(docs[1].Definitions, ShouldHaveLength, 1)
+		So(docs[1].Info.Title, ShouldEqual, api.Info.Title)
+	})
+
+	Convey("测试按接口数拆分文档", t, func() {
+		docs := apifox.SplitOpenApi2(api, apifox.SplitOptions{ByFolder: true, MaxOperations: 2})
+		So(docs, ShouldHaveLength, 3)
+		// 按目录排序：宠物商城/商店 < 宠物商城/宠物管理
+		So(docs[0].Paths.Paths["/store"].Get, ShouldNotBeNil)
+		So(docs[0].Paths.Paths["/pet"].Get, ShouldNotBeNil)
+		So(docs[0].Paths.Paths["/pet"].Post, ShouldBeNil)
+		So(docs[0].Definitions, ShouldHaveLength, 3)
+		So(docs[1].Paths.Paths["/pet"].Post, ShouldNotBeNil)
+		So(docs[1].Definitions, ShouldHaveLength, 2)
+		So(docs[1].Definitions, ShouldNotContainKey, "Store")
+		So(docs[2].Paths.Paths, ShouldContainKey, "/user")
+	})
+
+	Convey("测试分批导入并合并结果", t, func() {
+		requests := int32(0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"success":false,"errorMessage":"数据格式错误"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":{"apiCollection":{"item":{"createCount":1,"updateCount":1}}}}`))
+		}))
+		defer server.Close()
+
+		client := apifox.NewClient("1", "a")
+		client.BaseUrl = server.URL
+		docs := apifox.SplitOpenApi2(api, apifox.SplitOptions{ByFolder: true, MaxOperations: 2})
+		result, err := client.ImportBatches(context.Background(), docs, apifox.ImportOptions{}, 1)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "第 2/3 批导入出错")
+		So(requests, ShouldEqual, 3)
+		So(result.Success, ShouldBeFalse)
+		So(result.ErrorMessage, ShouldContainSubstring, "数据格式错误")
+		So(result.Data.ApiCollection.Item.CreateCount, ShouldEqual, 2)
+		So(result.Data.ApiCollection.Item.UpdateCount, ShouldEqual, 2)
+
+		atomic.StoreInt32(&requests, 10)
+		result, err = client.ImportBatches(context.Background(), docs, apifox.ImportOptions{}, 3)
+		So(err, ShouldBeNil)
+		So(result.Success, ShouldBeTrue)
+		So(result.Data.ApiCollection.Item.CreateCount, ShouldEqual, 3)
+	})
+}
+
+func TestNewPreview(t *testing.T) {
+	newOp := func(summary, folder string, params ...*spec.Parameter) *spec.Operation {
+		op := spec.NewOperation("").WithSummary(summary)
+		op.AddExtension(apifox.XFolder, folder)
+		for _, param := range params {
+			op.AddParam(param)
+		}
+		return op
 	}
+	remote := apifox.NewOpenApi2()
+	remote.Paths.Paths["/pet"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get:  newOp("查询宠物", "宠物", spec.QueryParam("id").Typed("string", "")),
+		Post: newOp("新建宠物", "宠物"),
+		Put:  newOp("修改宠物", "宠物"),
+	}}
+	remote.Paths.Paths["/store"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get: newOp("查询商店", "商店"),
+	}}
 
-	fmt.Println(string(resultData))
-	// Output:
-	// {"success":false,"data":{"apiCollection":{"item":{"createCount":0,"updateCount":0,"errorCount":0,"ignoreCount":0},"folder":{"createCount":0,"updateCount":0,"errorCount":0,"ignoreCount":0}},"schemaCollection":{"item":{"createCount":0,"updateCount":0,"errorCount":0,"ignoreCount":0},"folder":{"createCount":0,"updateCount":0,"errorCount":0,"ignoreCount":0}}}}
+	local := apifox.NewOpenApi2()
+	local.Paths.Paths["/pet"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get:  newOp("查询宠物", "宠物", spec.QueryParam("id").Typed("integer", "")),
+		Post: newOp("新建宠物", "宠物"),
+		Put:  newOp("修改宠物信息", "宠物"),
+	}}
+	local.Paths.Paths["/user"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get: newOp("查询用户", "用户"),
+	}}
+
+	Convey("测试预览同步结果", t, func() {
+		preview := apifox.NewPreview(remote, local, "methodAndPath", nil)
+		So(preview.Items, ShouldHaveLength, 5)
+		So(preview.Count(apifox.PreviewCreate), ShouldEqual, 1)
+		So(preview.Count(apifox.PreviewUpdate), ShouldEqual, 2)
+		So(preview.Count(apifox.PreviewUnchanged), ShouldEqual, 1)
+		So(preview.Count(apifox.PreviewOrphaned), ShouldEqual, 1)
+
+		So(preview.Items[0].Action, ShouldEqual, apifox.PreviewCreate)
+		So(preview.Items[0].Path, ShouldEqual, "/user")
+		So(preview.Items[4].Action, ShouldEqual, apifox.PreviewOrphaned)
+		So(preview.Items[4].Name, ShouldEqual, "商店/查询商店")
+
+		changes := make([]string, 0)
+		for _, item := range preview.Items {
+			if item.Action == apifox.PreviewUpdate {
+				changes = append(changes, item.Changes...)
+			}
+		}
+		So(strings.Join(changes, "\n"), ShouldContainSubstring, "修改宠物信息")
+		So(strings.Join(changes, "\n"), ShouldContainSubstring, "string")
+
+		var table strings.Builder
+		preview.Table(&table)
+		So(table.String(), ShouldContainSubstring, "orphaned  GET /store")
+		So(table.String(), ShouldNotContainSubstring, "POST /pet")
+	})
+
+	Convey("测试忽略已存在的接口", t, func() {
+		preview := apifox.NewPreview(remote, local, "ignore", nil)
+		So(preview.Count(apifox.PreviewIgnore), ShouldEqual, 3)
+		So(preview.Count(apifox.PreviewCreate), ShouldEqual, 1)
+
+		// 不传覆盖模式时 Apifox 会忽略已存在的接口
+		preview = apifox.NewPreview(remote, local, "", nil)
+		So(preview.Count(apifox.PreviewIgnore), ShouldEqual, 3)
+		So(preview.Count(apifox.PreviewCreate), ShouldEqual, 1)
+		So(preview.Count(apifox.PreviewUpdate), ShouldEqual, 0)
+	})
+
+	Convey("测试只在筛选范围内检查多余的接口", t, func() {
+		f, err := filter.New(filter.Options{Folders: []string{"宠物/**"}})
+		So(err, ShouldBeNil)
+		scoped := apifox.NewOpenApi2()
+		scoped.Paths.Paths["/pet"] = local.Paths.Paths["/pet"]
+
+		preview := apifox.NewPreview(remote, scoped, "methodAndPath", f.MatchRemote)
+		So(preview.Count(apifox.PreviewOrphaned), ShouldEqual, 0)
+		So(preview.Count(apifox.PreviewUpdate), ShouldEqual, 2)
+
+		// 删除了 Apifox 中还存在的宠物接口
+		scoped.Paths.Paths["/pet"] = spec.PathItem{PathItemProps: spec.PathItemProps{Get: local.Paths.Paths["/pet"].Get}}
+		preview = apifox.NewPreview(remote, scoped, "methodAndPath", f.MatchRemote)
+		So(preview.Count(apifox.PreviewOrphaned), ShouldEqual, 2)
+
+		// 按 Go 包筛选时无法判断
+		f, err = filter.New(filter.Options{Packages: []string{"petshop/..."}})
+		So(err, ShouldBeNil)
+		preview = apifox.NewPreview(remote, scoped, "methodAndPath", f.MatchRemote)
+		So(preview.Count(apifox.PreviewOrphaned), ShouldEqual, 0)
+	})
+
+	Convey("测试多个同步目标共用一个项目时不互相报告多余的接口", t, func() {
+		petFilter, err := filter.New(filter.Options{Folders: []string{"宠物/**"}})
+		So(err, ShouldBeNil)
+		storeFilter, err := filter.New(filter.Options{Folders: []string{"商店"}})
+		So(err, ShouldBeNil)
+
+		pets := apifox.NewOpenApi2()
+		pets.Paths.Paths["/pet"] = remote.Paths.Paths["/pet"]
+		stores := apifox.NewOpenApi2()
+		stores.Paths.Paths["/store"] = remote.Paths.Paths["/store"]
+
+		preview := apifox.NewPreview(remote, pets, "methodAndPath", petFilter.MatchRemote)
+		So(preview.Count(apifox.PreviewOrphaned), ShouldEqual, 0)
+		So(preview.Count(apifox.PreviewUnchanged), ShouldEqual, 3)
+		preview = apifox.NewPreview(remote, stores, "methodAndPath", storeFilter.MatchRemote)
+		So(preview.Count(apifox.PreviewOrphaned), ShouldEqual, 0)
+		So(preview.Count(apifox.PreviewUnchanged), ShouldEqual, 1)
+	})
+
+	Convey("测试导出 Apifox 项目中的接口", t, func() {
+		var lastReq *http.Request
+		var reqBody map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastReq = r
+			_ = json.NewDecoder(r.Body).Decode(&reqBody)
+			_ = json.NewEncoder(w).Encode(remote)
+		}))
+		defer server.Close()
+
+		client := apifox.NewClient("1", "a")
+		client.BaseUrl = server.URL
+		doc, err := client.ExportOpenApi2(context.Background())
+		So(err, ShouldBeNil)
+		So(lastReq.URL.Path, ShouldEqual, "/v1/projects/1/export-openapi")
+		So(lastReq.Header.Get("X-Apifox-Api-Version"), ShouldEqual, apifox.ExportVersion)
+		So(reqBody["oasVersion"], ShouldEqual, "2.0")
+		So(doc.Paths.Paths, ShouldContainKey, "/store")
+	})
 }
 
-func ExampleOpenApi2() {
+func ExampleOpenApi2AddPaths() {
 	goParser := parser.NewParser()
 	_, err := goParser.Scan("../example/petshop/pet")
 	if err != nil {
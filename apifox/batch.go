@@ -0,0 +1,272 @@
+package apifox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SplitOptions 拆分文档的选项
+type SplitOptions struct {
+	ByFolder      bool // 按一级目录（x-apifox-folder 的第一级）拆分
+	MaxOperations int  // 每个文档最多包含的接口数，0 表示不限制
+}
+
+// operation 文档中的一个接口
+type operation struct {
+	path   string
+	method string
+	op     *spec.Operation
+}
+
+// SplitOpenApi2 将文档拆分为多个独立的文档，每个文档只包含其中接口用到的数据模型（definitions）。
+//
+//	先按一级目录拆分，再按接口数拆分；接口按目录、路径、请求方式排序，保证每次拆分的结果一致。
+func SplitOpenApi2(api *spec.Swagger, opts SplitOptions) []*spec.Swagger {
+	ops := openApi2Operations(api)
+	if !opts.ByFolder && (opts.MaxOperations <= 0 || len(ops) <= opts.MaxOperations) {
+		return []*spec.Swagger{api}
+	}
+
+	// 按一级目录分组
+	groups := make([][]operation, 0)
+	{
+		index := make(map[string]int)
+		for _, op := range ops {
+			key := ""
+			if opts.ByFolder {
+				key = topFolder(operationFolder(op.op))
+			}
+			i, ok := index[key]
+			if !ok {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], op)
+		}
+	}
+
+	docs := make([]*spec.Swagger, 0)
+	for _, group := range groups {
+		// 按接口数拆分
+		size := len(group)
+		if opts.MaxOperations > 0 {
+			size = opts.MaxOperations
+		}
+		for start := 0; start < len(group); start += size {
+			end := start + size
+			if end > len(group) {
+				end = len(group)
+			}
+			docs = append(docs, newOpenApi2Batch(api, group[start:end]))
+		}
+	}
+	return docs
+}
+
+// openApi2Operations 获取文档中的所有接口，按目录、路径、请求方式排序
+func openApi2Operations(api *spec.Swagger) []operation {
+	ops := make([]operation, 0)
+	if api.Paths == nil {
+		return ops
+	}
+	for path, item := range api.Paths.Paths {
+		item := item
+		for method, op := range map[string]*spec.Operation{
+			http.MethodGet:     item.Get,
+			http.MethodPut:     item.Put,
+			http.MethodPost:    item.Post,
+			http.MethodDelete:  item.Delete,
+			http.MethodOptions: item.Options,
+			http.MethodHead:    item.Head,
+			http.MethodPatch:   item.Patch,
+		} {
+			if op != nil {
+				ops = append(ops, operation{path: path, method: method, op: op})
+			}
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		fi, fj := operationFolder(ops[i].op), operationFolder(ops[j].op)
+		if fi != fj {
+			return fi < fj
+		}
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+	return ops
+}
+
+// operationFolder 获取接口所属目录
+func operationFolder(op *spec.Operation) string {
+	folder, _ := op.Extensions.GetString(XFolder)
+	return folder
+}
+
+// topFolder 获取一级目录，\/ 表示字符 /，不作为目录分隔符
+func topFolder(folder string) string {
+	for i := 0; i < len(folder); i++ {
+		switch folder[i] {
+		case '\\':
+			i++
+		case '/':
+			return folder[:i]
+		}
+	}
+	return folder
+}
+
+// newOpenApi2Batch 使用原文档的基本信息和指定的接口生成新文档
+func newOpenApi2Batch(api *spec.Swagger, ops []operation) *spec.Swagger {
+	doc := &spec.Swagger{
+		VendorExtensible: api.VendorExtensible,
+		SwaggerProps:     api.SwaggerProps,
+	}
+	doc.Paths = &spec.Paths{
+		VendorExtensible: api.Paths.VendorExtensible,
+		Paths:            make(map[string]spec.PathItem),
+	}
+	for _, op := range ops {
+		item := doc.Paths.Paths[op.path]
+		switch op.method {
+		case http.MethodGet:
+			item.Get = op.op
+		case http.MethodPut:
+			item.Put = op.op
+		case http.MethodPost:
+			item.Post = op.op
+		case http.MethodDelete:
+			item.Delete = op.op
+		case http.MethodOptions:
+			item.Options = op.op
+		case http.MethodHead:
+			item.Head = op.op
+		case http.MethodPatch:
+			item.Patch = op.op
+		}
+		item.Parameters = api.Paths.Paths[op.path].Parameters
+		doc.Paths.Paths[op.path] = item
+	}
+	doc.Definitions = usedDefinitions(api.Definitions, doc.Paths)
+	return doc
+}
+
+var definitionRefRegexp = regexp.MustCompile(`"\$ref":"#/definitions/([^"]+)"`)
+
+// usedDefinitions 获取接口直接或间接用到的数据模型
+func usedDefinitions(definitions spec.Definitions, paths *spec.Paths) spec.Definitions {
+	if len(definitions) == 0 {
+		return nil
+	}
+	used := make(spec.Definitions)
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		for _, match := range definitionRefRegexp.FindAllSubmatch(data, -1) {
+			name := strings.ReplaceAll(strings.ReplaceAll(string(match[1]), "~1", "/"), "~0", "~")
+			schema, ok := definitions[name]
+			if _, exists := used[name]; exists || !ok {
+				continue
+			}
+			used[name] = schema
+			collect(schema)
+		}
+	}
+	collect(paths)
+	return used
+}
+
+// ImportBatches 依次导入多个文档，concurrency 大于 1 时并发导入，并将导入结果按文档顺序合并。
+//
+//	某个文档导入出错时，继续导入其他文档，返回合并后的结果和第一个错误。
+func (c *Client) ImportBatches(ctx context.Context, docs []*spec.Swagger, opts ImportOptions, concurrency int) (*ImportDataResult, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]*ImportDataResult, len(docs))
+	errs := make([]error, len(docs))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, data []byte) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = c.ImportData(ctx, string(data), opts)
+		}(i, data)
+	}
+	wg.Wait()
+
+	merged := &ImportDataResult{Success: true}
+	raws := make([]json.RawMessage, 0, len(docs))
+	var firstErr error
+	for i := range docs {
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("第 %d/%d 批导入出错: %w", i+1, len(docs), errs[i])
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			merged.Merge(&ImportDataResult{ErrorMessage: errs[i].Error()})
+			continue
+		}
+		merged.Merge(results[i])
+		if results[i].Raw != nil {
+			raws = append(raws, results[i].Raw)
+		}
+	}
+	if len(raws) == 1 {
+		merged.Raw = raws[0]
+	} else if len(raws) > 1 {
+		merged.Raw, _ = json.Marshal(raws)
+	}
+	return merged, firstErr
+}
+
+// Merge 合并其他的导入结果，有一个导入失败则整体失败
+func (p *ImportDataResult) Merge(other *ImportDataResult) {
+	p.Success = p.Success && other.Success
+	if other.ErrorCode != "" && p.ErrorCode == "" {
+		p.ErrorCode = other.ErrorCode
+	}
+	if other.ErrorMessage != "" {
+		if p.ErrorMessage != "" {
+			p.ErrorMessage += "; "
+		}
+		p.ErrorMessage += other.ErrorMessage
+	}
+	p.Data.ApiCollection.merge(other.Data.ApiCollection)
+	p.Data.SchemaCollection.merge(other.Data.SchemaCollection)
+}
+
+func (p *ImportDataResultCollection) merge(other ImportDataResultCollection) {
+	p.Item.merge(other.Item)
+	p.Folder.merge(other.Folder)
+}
+
+func (p *ImportDataResultItem) merge(other ImportDataResultItem) {
+	p.CreateCount += other.CreateCount
+	p.UpdateCount += other.UpdateCount
+	p.ErrorCount += other.ErrorCount
+	p.IgnoreCount += other.IgnoreCount
+}
@@ -121,13 +121,14 @@ func OpenApi2AddPaths(api *spec.Swagger, apiItems []parser.ApiItem) {
 		operation := &spec.Operation{
 			VendorExtensible: spec.VendorExtensible{
 				Extensions: map[string]interface{}{
-					XFolder: apiItem.Folder,
-					XStatus: apiItem.Status,
+					XFolder: apiItem.Folder.String(),
+					XStatus: string(apiItem.Status),
 				},
 			},
 			OperationProps: spec.OperationProps{
 				Summary:     apiItem.Title,
 				Description: apiItem.Description,
+				Tags:        apiItem.Tags,
 				Consumes:    consumes,
 				Parameters:  parameters,
 				Produces:    produces,
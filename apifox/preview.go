@@ -0,0 +1,173 @@
+package apifox
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/whaios/apigo/diff"
+	"github.com/whaios/apigo/parser"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// 预览同步时接口的处理结果
+const (
+	PreviewCreate    = "create"    // 新建接口
+	PreviewUpdate    = "update"    // 覆盖修改接口
+	PreviewUnchanged = "unchanged" // 接口没有变化
+	PreviewIgnore    = "ignore"    // 接口已存在，不导入
+	PreviewOrphaned  = "orphaned"  // 只存在于 Apifox 中，本地已没有该接口
+)
+
+// previewActions 按固定顺序输出
+var previewActions = []string{PreviewCreate, PreviewUpdate, PreviewUnchanged, PreviewIgnore, PreviewOrphaned}
+
+// PreviewItem 同步时单个接口的处理结果
+type PreviewItem struct {
+	Action  string   `json:"action"`            // 处理结果，见 Preview 开头的常量
+	Method  string   `json:"method"`            // 接口请求方式
+	Path    string   `json:"path"`              // 接口请求路径
+	Name    string   `json:"name"`              // 接口名称（目录+标题）
+	Changes []string `json:"changes,omitempty"` // 修改的内容
+}
+
+// Preview 预览同步结果，不导入任何数据
+type Preview struct {
+	Items []PreviewItem `json:"items"`
+}
+
+// NewPreview 比较 Apifox 项目中当前的接口和本地生成的文档，预览同步后每个接口的处理结果。
+//
+//	apiOverwriteMode 为匹配到相同接口时的覆盖模式：ignore 或不传时已存在的接口不导入，both 时总是新建接口。
+//	inScope 判断 Apifox 中的接口是否在本次同步的范围内（如筛选条件），只有范围内的接口才会被当作多余的接口，为 nil 时不限制。
+func NewPreview(remote, local *spec.Swagger, apiOverwriteMode string, inScope func(item *parser.ApiItem) bool) *Preview {
+	changes := make(map[string][]string)
+	for _, change := range diff.Compare(remote, local).Changes {
+		if change.Kind == diff.KindEndpointAdded || change.Kind == diff.KindEndpointRemoved {
+			continue
+		}
+		changes[change.Endpoint()] = append(changes[change.Endpoint()], change.Message)
+	}
+
+	preview := &Preview{Items: make([]PreviewItem, 0)}
+	remoteOps := make(map[string]*spec.Operation)
+	for _, op := range openApi2Operations(remote) {
+		remoteOps[op.method+" "+op.path] = op.op
+	}
+	for _, op := range openApi2Operations(local) {
+		key := op.method + " " + op.path
+		item := PreviewItem{Method: op.method, Path: op.path, Name: operationName(op.op)}
+		remoteOp, exists := remoteOps[key]
+		delete(remoteOps, key)
+		switch {
+		case !exists || apiOverwriteMode == "both":
+			item.Action = PreviewCreate
+		case apiOverwriteMode == "ignore" || apiOverwriteMode == "":
+			item.Action = PreviewIgnore
+		default:
+			item.Changes = append(operationInfoChanges(remoteOp, op.op), changes[key]...)
+			item.Action = PreviewUnchanged
+			if len(item.Changes) > 0 {
+				item.Action = PreviewUpdate
+			}
+		}
+		preview.Items = append(preview.Items, item)
+	}
+	for _, op := range openApi2Operations(remote) {
+		if _, orphaned := remoteOps[op.method+" "+op.path]; orphaned && (inScope == nil || inScope(operationApiItem(op))) {
+			preview.Items = append(preview.Items, PreviewItem{
+				Action: PreviewOrphaned,
+				Method: op.method,
+				Path:   op.path,
+				Name:   operationName(op.op),
+			})
+		}
+	}
+	sort.SliceStable(preview.Items, func(i, j int) bool {
+		return actionIndex(preview.Items[i].Action) < actionIndex(preview.Items[j].Action)
+	})
+	return preview
+}
+
+// operationApiItem 将 Apifox 中的接口转为接口文档，用于筛选，其中没有 Go 包信息
+func operationApiItem(op operation) *parser.ApiItem {
+	item := &parser.ApiItem{
+		Title:  op.op.Summary,
+		Tags:   op.op.Tags,
+		Method: strings.ToLower(op.method),
+		Path:   op.path,
+	}
+	item.Folder, _ = parser.ParseFolder(operationFolder(op.op))
+	status, _ := op.op.Extensions.GetString(XStatus)
+	item.Status, _ = parser.ParseStatus(status)
+	return item
+}
+
+// operationName 接口名称（目录+标题）
+func operationName(op *spec.Operation) string {
+	if folder := operationFolder(op); folder != "" {
+		return folder + "/" + op.Summary
+	}
+	return op.Summary
+}
+
+// operationInfoChanges 比较接口的名称、目录、状态和说明
+func operationInfoChanges(remote, local *spec.Operation) []string {
+	changes := make([]string, 0)
+	if remote.Summary != local.Summary {
+		changes = append(changes, fmt.Sprintf("接口名称 %q 改为 %q", remote.Summary, local.Summary))
+	}
+	if remoteFolder, localFolder := operationFolder(remote), operationFolder(local); remoteFolder != localFolder {
+		changes = append(changes, fmt.Sprintf("接口目录 %q 改为 %q", remoteFolder, localFolder))
+	}
+	// 本地没有指定状态时，不修改 Apifox 中的状态
+	remoteStatus, _ := remote.Extensions.GetString(XStatus)
+	if localStatus, _ := local.Extensions.GetString(XStatus); localStatus != "" && remoteStatus != localStatus {
+		changes = append(changes, fmt.Sprintf("接口状态 %q 改为 %q", remoteStatus, localStatus))
+	}
+	if strings.TrimSpace(remote.Description) != strings.TrimSpace(local.Description) {
+		changes = append(changes, "修改接口说明")
+	}
+	return changes
+}
+
+func actionIndex(action string) int {
+	for i, a := range previewActions {
+		if a == action {
+			return i
+		}
+	}
+	return len(previewActions)
+}
+
+// Count 统计指定处理结果的接口数
+func (p *Preview) Count(action string) int {
+	n := 0
+	for _, item := range p.Items {
+		if item.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Table 以表格形式输出预览结果，没有变化的接口只统计数量
+func (p *Preview) Table(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "结果\t接口\t名称\t修改内容")
+	for _, item := range p.Items {
+		if item.Action == PreviewUnchanged {
+			continue
+		}
+		changes := strings.Join(item.Changes, "; ")
+		_, _ = fmt.Fprintf(tw, "%s\t%s %s\t%s\t%s\n", item.Action, item.Method, item.Path, item.Name, changes)
+	}
+	_ = tw.Flush()
+
+	counts := make([]string, 0, len(previewActions))
+	for _, action := range previewActions {
+		counts = append(counts, fmt.Sprintf("%s %d", action, p.Count(action)))
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(counts, "，"))
+}
@@ -0,0 +1,55 @@
+package apifox
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whaios/apigo/parser"
+	"io"
+	"text/tabwriter"
+)
+
+// NewImportReport 根据导入结果生成导入报告
+func NewImportReport(items []parser.ApiItem, result *ImportDataResult) *ImportReport {
+	return &ImportReport{
+		Success:      result.Success,
+		ErrorCode:    result.ErrorCode,
+		ErrorMessage: result.ErrorMessage,
+		Total:        len(items),
+		Api:          result.Data.ApiCollection.Item,
+		ApiFolder:    result.Data.ApiCollection.Folder,
+		Schema:       result.Data.SchemaCollection.Item,
+		Raw:          result.Raw,
+	}
+}
+
+// ImportReport 导入报告
+type ImportReport struct {
+	Success      bool   `json:"success"`      // 是否导入成功
+	ErrorCode    string `json:"errorCode"`    // 失败时的错误码
+	ErrorMessage string `json:"errorMessage"` // 失败时的错误信息
+
+	Total     int                  `json:"total"`     // 上传的接口数
+	Api       ImportDataResultItem `json:"api"`       // 导入接口情况
+	ApiFolder ImportDataResultItem `json:"apiFolder"` // 导入接口目录情况
+	Schema    ImportDataResultItem `json:"schema"`    // 导入数据模型情况
+
+	Raw json.RawMessage `json:"raw,omitempty"` // Apifox 返回的原始数据
+}
+
+// HasError 是否导入失败或有导入出错的接口、数据模型
+func (r *ImportReport) HasError() bool {
+	return !r.Success || r.Api.ErrorCount > 0 || r.Schema.ErrorCount > 0
+}
+
+// Table 以表格形式输出导入报告
+func (r *ImportReport) Table(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !r.Success {
+		_, _ = fmt.Fprintf(tw, "同步失败\t%s\t%s\n", r.ErrorCode, r.ErrorMessage)
+	}
+	_, _ = fmt.Fprintln(tw, "类型\t新增\t修改\t出错\t忽略")
+	_, _ = fmt.Fprintf(tw, "接口\t%d\t%d\t%d\t%d\n", r.Api.CreateCount, r.Api.UpdateCount, r.Api.ErrorCount, r.Api.IgnoreCount)
+	_, _ = fmt.Fprintf(tw, "接口目录\t%d\t%d\t%d\t%d\n", r.ApiFolder.CreateCount, r.ApiFolder.UpdateCount, r.ApiFolder.ErrorCount, r.ApiFolder.IgnoreCount)
+	_, _ = fmt.Fprintf(tw, "数据模型\t%d\t%d\t%d\t%d\n", r.Schema.CreateCount, r.Schema.UpdateCount, r.Schema.ErrorCount, r.Schema.IgnoreCount)
+	_ = tw.Flush()
+}
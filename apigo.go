@@ -2,24 +2,33 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/go-openapi/spec"
 	"github.com/urfave/cli/v2"
 	"github.com/whaios/apigo/apifox"
+	"github.com/whaios/apigo/filter"
 	"github.com/whaios/apigo/log"
 	"github.com/whaios/apigo/parser"
-	"io/fs"
+	"github.com/whaios/apigo/watch"
 	"os"
 )
 
-// 环境变量
-const (
-	EnvApifoxProjectId   = "ApifoxProjectId"   // 项目 ID
-	EnvApifoxAccessToken = "ApifoxAccessToken" // 个人访问令牌
-)
-
 const (
 	flagDir     = "dir"
 	flagOutFile = "outfile"
+	flagWatch   = "watch"
+
+	flagFolder        = "folder"
+	flagExcludeFolder = "excludeFolder"
+	flagPkg           = "pkg"
+	flagExcludePkg    = "excludePkg"
+	flagTag           = "tag"
+	flagExcludeTag    = "excludeTag"
+	flagStatus        = "status"
+	flagExcludeStatus = "excludeStatus"
+	flagMethod        = "method"
+	flagPath          = "path"
+	flagExcludePath   = "excludePath"
+	categoryFilter    = "筛选接口"
 )
 
 func main() {
@@ -42,77 +51,16 @@ func main() {
 		},
 	}
 	app.Commands = []*cli.Command{
-		{
-			Name:    "apifox",
-			Aliases: []string{"af"},
-			Usage:   "快速生成 API 文档，并同步到 Apifox。",
-			Flags: []cli.Flag{
-				&cli.StringFlag{
-					Name:        "project",
-					Aliases:     []string{"p"},
-					Usage:       "项目 ID",
-					Value:       apifox.ProjectId,
-					Destination: &apifox.ProjectId,
-					EnvVars:     []string{EnvApifoxProjectId},
-				},
-				&cli.StringFlag{
-					Name:        "token",
-					Aliases:     []string{"t"},
-					Usage:       "个人访问令牌",
-					Value:       apifox.AccessToken,
-					Destination: &apifox.AccessToken,
-					EnvVars:     []string{EnvApifoxAccessToken},
-				},
-				&cli.StringFlag{
-					Name:    flagDir,
-					Aliases: []string{"d"},
-					Value:   "",
-					Usage:   "要解析的 Go 源码文件的目录，该目录下必须有 Go 源码文件。",
-					//Required: true,
-				},
-				&cli.StringFlag{
-					Name:    flagOutFile,
-					Aliases: []string{"of"},
-					Value:   "",
-					Usage:   "将生成的文档数据导出到指定文件，不上传到 Apifox。",
-				},
-				&cli.StringFlag{
-					Name:        "apiOverwriteMode",
-					Value:       "methodAndPath",
-					Usage:       "匹配到相同接口时的覆盖模式，不传表示忽略。枚举值: methodAndPath=覆盖，both=保留两者，merge=智能合并，ignore=不导入",
-					Destination: &apifox.ApiOverwriteMode,
-				},
-				&cli.StringFlag{
-					Name:        "schemaOverwriteMode",
-					Value:       "",
-					Usage:       "匹配到相同数据模型时的覆盖模式，不传表示忽略。枚举值: name=覆盖，both=保留两者，merge=智能合并，ignore=不导入",
-					Destination: &apifox.SchemaOverwriteMode,
-				},
-				&cli.BoolFlag{
-					Name:        "syncApiFolder",
-					Value:       false,
-					Usage:       "是否同步更新接口所在目录（默认值: false）",
-					Destination: &apifox.SyncApiFolder,
-				},
-			},
-			Action: func(c *cli.Context) error {
-				apifoxImptData(c.Context, c.String(flagDir), c.String(flagOutFile))
-				return nil
-			},
-			Subcommands: []*cli.Command{
-				{
-					Name:  "flags",
-					Usage: "查询相关参数。",
-					Action: func(c *cli.Context) error {
-						log.Info("baseUrl=%s", apifox.BaseUrl)
-						log.Info("projectId=%s", apifox.ProjectId)
-						log.Info("accessToken=%s", apifox.AccessToken)
-						log.Info("isDebug=%v", log.IsDebug)
-						return nil
-					},
-				},
-			},
-		},
+		apifoxCommand(),
+		syncCommand(),
+		diffCommand(),
+		serveCommand(),
+		mockCommand(),
+		exportCommand(),
+		annotateCommand(),
+		migrateCommand(),
+		fmtCommand(),
+		coverageCommand(),
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -120,64 +68,128 @@ func main() {
 	}
 }
 
-// 解析 go 注释生成 API 文档，并导入到 apifox。
-func apifoxImptData(ctx context.Context, dir, outFile string) {
-	log.StartSpinner(ctx)
-	defer log.StopSpinner()
+// watchParser 监听代码目录和解析过的外部包目录，重新解析变化的文件后调用 onUpdate，直到 ctx 结束。
+func watchParser(ctx context.Context, goParser *parser.Parser, onUpdate func()) {
+	log.Info(log.UpdateSpinner("监听文件变化..."))
+	w := watch.New(goParser.Scanner().Dirs)
+	_ = w.Run(ctx, func(files []string) {
+		for _, file := range files {
+			log.Info("文件变化 %s", file)
+		}
+		// 出错的文件保留上次解析的结果，其他文件的变化仍然生效
+		if err := goParser.Update(files...); err != nil {
+			log.Error(err.Error())
+		}
+		onUpdate()
+		log.Info(log.UpdateSpinner("监听文件变化..."))
+	})
+}
 
+// filterFlags 筛选接口的参数，同一参数可以指定多次
+func filterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     flagFolder,
+			Usage:    "只包含指定目录中的接口，* 匹配一级目录中的任意字符，** 匹配任意多级目录，如：宠物商城/**",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagExcludeFolder,
+			Usage:    "排除指定目录中的接口，格式同 --folder",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagPkg,
+			Usage:    "只包含指定 Go 包中的接口，... 匹配任意字符，如：petshop/pet/...",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagExcludePkg,
+			Usage:    "排除指定 Go 包中的接口，格式同 --pkg",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagTag,
+			Usage:    "只包含有指定标签（@tag）的接口",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagExcludeTag,
+			Usage:    "排除有指定标签（@tag）的接口",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagStatus,
+			Usage:    "只包含指定状态的接口，支持别名，如：released 或 已发布",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagExcludeStatus,
+			Usage:    "排除指定状态的接口，如：designing",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagMethod,
+			Usage:    "只包含指定请求方式的接口，如：GET",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagPath,
+			Usage:    "只包含请求路径匹配正则表达式的接口，如：^/pet",
+			Category: categoryFilter,
+		},
+		&cli.StringSliceFlag{
+			Name:     flagExcludePath,
+			Usage:    "排除请求路径匹配正则表达式的接口",
+			Category: categoryFilter,
+		},
+	}
+}
+
+// newFilter 根据命令行参数创建接口筛选器
+func newFilter(c *cli.Context) (*filter.Filter, error) {
+	return filter.New(filter.Options{
+		Folders:         c.StringSlice(flagFolder),
+		ExcludeFolders:  c.StringSlice(flagExcludeFolder),
+		Packages:        c.StringSlice(flagPkg),
+		ExcludePackages: c.StringSlice(flagExcludePkg),
+		Tags:            c.StringSlice(flagTag),
+		ExcludeTags:     c.StringSlice(flagExcludeTag),
+		Statuses:        c.StringSlice(flagStatus),
+		ExcludeStatuses: c.StringSlice(flagExcludeStatus),
+		Methods:         c.StringSlice(flagMethod),
+		Paths:           c.StringSlice(flagPath),
+		ExcludePaths:    c.StringSlice(flagExcludePath),
+	})
+}
+
+// scanDir 扫描指定目录中的 go 代码
+func scanDir(dir string) (*parser.Parser, error) {
 	log.Info("扫描目录 %s", dir)
 
 	parser.SetSchemaExtraPropertiesOrdersKey(apifox.XOrders)
 	goParser := parser.NewParser()
 	fileCount, err := goParser.Scan(dir)
 	if err != nil {
-		log.Error(err.Error())
-		return
+		return nil, err
 	}
 
 	log.Info("采集到%d个Go代码文件", fileCount)
+	return goParser, nil
+}
 
-	// 解析每个文件并生成接口文档
-	items, err := goParser.Parse()
+// parseApiItems 扫描指定目录中的 go 代码，并解析注释生成 API 接口文档。
+func parseApiItems(dir string) ([]parser.ApiItem, error) {
+	goParser, err := scanDir(dir)
 	if err != nil {
-		log.Error(err.Error())
-		return
+		return nil, err
 	}
+	return goParser.Parse()
+}
 
+// newOpenApi2 将 API 接口文档转换为 OpenAPI 2.0（Swagger）文档。
+func newOpenApi2(items []parser.ApiItem) *spec.Swagger {
 	api2 := apifox.NewOpenApi2()
 	apifox.OpenApi2AddPaths(api2, items)
-
-	api2JsonData, err := json.MarshalIndent(api2, "", "    ")
-	if err != nil {
-		log.Error(err.Error())
-		return
-	}
-
-	// 导出到文件
-	if outFile != "" {
-		log.Debug(log.UpdateSpinner("导出到文件 %s", outFile))
-
-		if err = os.WriteFile(outFile, api2JsonData, fs.ModePerm); err != nil {
-			log.Error(err.Error())
-			return
-		}
-		log.Success("导出文件成功 %s", outFile)
-		return
-	}
-
-	log.Debug(log.UpdateSpinner("同步到 Apifox"))
-
-	// 上传到 Apifox 服务器
-	result, err := apifox.PostImportData(string(api2JsonData))
-	if err != nil {
-		log.Error(err.Error())
-		return
-	}
-	if result.Success {
-		log.Info(result.String())
-		log.Success("同步 Apifox 成功")
-	} else {
-		log.Error("同步 Apifox 失败")
-	}
-	return
+	return api2
 }
@@ -0,0 +1,65 @@
+// Package apigotest 在测试中校验接口的响应是否与注释生成的文档一致。
+//
+// 使用示例：
+//
+//	var spec = apigotest.MustLoad("openapi.json")
+//
+//	func TestGetPet(t *testing.T) {
+//		rec := httptest.NewRecorder()
+//		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/pet/1", nil))
+//		apigotest.AssertResponse(t, spec, "GET", "/pet/{petId}", rec)
+//	}
+package apigotest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/whaios/apigo/runtime"
+	"net/http/httptest"
+	"strings"
+)
+
+// TestingT testing.T 中用到的方法
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// MustLoad 加载 OpenAPI 2.0（Swagger）格式的 json 文件，出错时 panic
+func MustLoad(file string) *runtime.Spec {
+	s, err := runtime.LoadFile(file)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// AssertResponse 根据请求方式和路径查找接口，按响应状态码选择文档中的响应，校验记录的 json 响应内容是否与其数据结构一致。
+//
+//	path 可以是文档中定义的路径，如 /pet/{petId}，也可以是实际的请求路径，如 /pet/1。
+//	不一致时通过 t.Errorf 逐个输出出错字段的 JSON 指针路径，并返回 false。
+func AssertResponse(t TestingT, s *runtime.Spec, method, path string, rec *httptest.ResponseRecorder) bool {
+	t.Helper()
+
+	err := runtime.NewValidator(s).ValidateResponse(strings.ToUpper(method), path, rec.Code, rec.Header(), rec.Body.Bytes())
+	if err == nil {
+		return true
+	}
+
+	var ve *runtime.ValidationError
+	if !errors.As(err, &ve) {
+		t.Errorf("%s", err)
+		return false
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("响应 %s %s %d 与文档不一致:", strings.ToUpper(method), path, rec.Code))
+	for _, fe := range ve.Errors {
+		pointer := fe.Pointer
+		if pointer == "" {
+			pointer = "/"
+		}
+		sb.WriteString(fmt.Sprintf("\n\t%s: %s", pointer, fe.Message))
+	}
+	t.Errorf("%s", sb.String())
+	return false
+}
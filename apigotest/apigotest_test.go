@@ -0,0 +1,46 @@
+package apigotest
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/runtime"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeT struct {
+	errs []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+func TestAssertResponse(t *testing.T) {
+	Convey("测试校验响应内容", t, func() {
+		s, err := runtime.Load([]byte(`{"swagger":"2.0","paths":{"/pet/{petId}":{"get":{"responses":{"200":{"schema":{
+			"type":"object","required":["id"],"properties":{"id":{"type":"string"},"tags":{"type":"array","items":{"type":"string"}}}
+		}}}}}}}`))
+		So(err, ShouldBeNil)
+
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", "application/json")
+		_, _ = rec.WriteString(`{"id":"1","tags":["a"]}`)
+		ft := &fakeT{}
+		So(AssertResponse(ft, s, "GET", "/pet/{petId}", rec), ShouldBeTrue)
+		So(ft.errs, ShouldBeEmpty)
+
+		rec = httptest.NewRecorder()
+		_, _ = rec.WriteString(`{"tags":["a",1]}`)
+		So(AssertResponse(ft, s, "get", "/pet/1", rec), ShouldBeFalse)
+		So(ft.errs, ShouldResemble, []string{"响应 GET /pet/1 200 与文档不一致:\n\t/id: 缺少必填字段\n\t/tags/1: 类型应为 string，实际为 integer"})
+
+		ft = &fakeT{}
+		rec = httptest.NewRecorder()
+		rec.WriteHeader(500)
+		So(AssertResponse(ft, s, "GET", "/pet/{petId}", rec), ShouldBeFalse)
+		So(ft.errs, ShouldResemble, []string{"响应 GET /pet/{petId} 500 与文档不一致:\n\t/: 文档中没有定义 500 响应"})
+	})
+}
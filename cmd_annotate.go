@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/annotate"
+	"github.com/whaios/apigo/diff"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/log"
+)
+
+const flagFrom = "from"
+
+func annotateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "annotate",
+		Usage: "根据已有的 OpenAPI 2.0（Swagger）文档，在对应的 Go 方法上生成接口注释。",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagFrom,
+				Required: true,
+				Usage:    "OpenAPI 2.0（Swagger）格式的 json 文档，如从 Apifox 导出的文档。",
+			},
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "要添加注释的 Go 源码文件的目录。",
+			},
+			&cli.BoolFlag{
+				Name:  flagDryRun,
+				Usage: "只输出将要添加的注释，不修改代码文件。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			doc, err := diff.LoadFile(c.String(flagFrom))
+			if err != nil {
+				return err
+			}
+
+			log.StartSpinner(c.Context)
+			scanner := goscanner.New()
+			err = scanner.Scan(c.String(flagDir))
+			var report *annotate.Report
+			if err == nil {
+				report, err = annotate.Annotate(doc, scanner)
+			}
+			log.StopSpinner()
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(report.Text())
+			if c.Bool(flagDryRun) || report.Changed() == 0 {
+				return nil
+			}
+			if err = report.Write(); err != nil {
+				return err
+			}
+			log.Success("添加注释成功，共修改 %d 个方法", report.Changed())
+			return nil
+		},
+	}
+}
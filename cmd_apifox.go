@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/apifox"
+	"github.com/whaios/apigo/filter"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/parser"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// 环境变量
+const (
+	EnvApifoxProjectId   = "ApifoxProjectId"   // 项目 ID
+	EnvApifoxAccessToken = "ApifoxAccessToken" // 个人访问令牌
+	EnvApifoxBaseUrl     = "ApifoxBaseUrl"     // 开放接口地址
+)
+
+const (
+	flagProject             = "project"
+	flagToken               = "token"
+	flagBaseUrl             = "baseUrl"
+	flagProxy               = "proxy"
+	flagTimeout             = "timeout"
+	flagRetries             = "retries"
+	flagApiOverwriteMode    = "apiOverwriteMode"
+	flagSchemaOverwriteMode = "schemaOverwriteMode"
+	flagSyncApiFolder       = "syncApiFolder"
+	flagReport              = "report"
+	flagSplitByFolder       = "splitByFolder"
+	flagBatchSize           = "batchSize"
+	flagConcurrency         = "concurrency"
+	flagDryRun              = "dryRun"
+)
+
+func apifoxCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "apifox",
+		Aliases: []string{"af"},
+		Usage:   "快速生成 API 文档，并同步到 Apifox。",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    flagProject,
+				Aliases: []string{"p"},
+				Usage:   "项目 ID",
+				EnvVars: []string{EnvApifoxProjectId},
+			},
+			&cli.StringFlag{
+				Name:    flagToken,
+				Aliases: []string{"t"},
+				Usage:   "个人访问令牌",
+				EnvVars: []string{EnvApifoxAccessToken},
+			},
+			&cli.StringFlag{
+				Name:    flagBaseUrl,
+				Value:   apifox.DefaultBaseUrl,
+				Usage:   "开放接口地址，海外版使用 " + apifox.BaseUrlGlobal + "，私有化部署时使用部署的地址",
+				EnvVars: []string{EnvApifoxBaseUrl},
+			},
+			&cli.StringFlag{
+				Name:  flagProxy,
+				Usage: "HTTP 代理地址，如：http://127.0.0.1:7890，不传时使用环境变量 HTTP_PROXY、HTTPS_PROXY",
+			},
+			&cli.DurationFlag{
+				Name:  flagTimeout,
+				Value: apifox.DefaultTimeout,
+				Usage: "单次请求的超时时间",
+			},
+			&cli.IntFlag{
+				Name:  flagRetries,
+				Value: apifox.DefaultMaxRetries,
+				Usage: "返回 429 或 5xx 错误、请求发出之前出现连接错误时的最大重试次数",
+			},
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   "",
+				Usage:   "要解析的 Go 源码文件的目录，该目录下必须有 Go 源码文件。",
+				//Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagOutFile,
+				Aliases: []string{"of"},
+				Value:   "",
+				Usage:   "将生成的文档数据导出到指定文件，不上传到 Apifox。",
+			},
+			&cli.BoolFlag{
+				Name:    flagWatch,
+				Aliases: []string{"w"},
+				Value:   false,
+				Usage:   "监听代码文件变化，只重新解析变化的文件，并重新导出或同步。",
+			},
+			&cli.StringFlag{
+				Name:  flagApiOverwriteMode,
+				Value: "methodAndPath",
+				Usage: "匹配到相同接口时的覆盖模式，不传表示忽略。枚举值: methodAndPath=覆盖，both=保留两者，merge=智能合并，ignore=不导入",
+			},
+			&cli.StringFlag{
+				Name:  flagSchemaOverwriteMode,
+				Value: "",
+				Usage: "匹配到相同数据模型时的覆盖模式，不传表示忽略。枚举值: name=覆盖，both=保留两者，merge=智能合并，ignore=不导入",
+			},
+			&cli.BoolFlag{
+				Name:  flagSyncApiFolder,
+				Value: false,
+				Usage: "是否同步更新接口所在目录（默认值: false）",
+			},
+			&cli.StringFlag{
+				Name:  flagReport,
+				Value: "",
+				Usage: "将同步结果以 JSON 格式写入到指定文件，可作为 CI 的构建产物。",
+			},
+			&cli.BoolFlag{
+				Name:  flagSplitByFolder,
+				Value: false,
+				Usage: "按一级目录拆分为多个文档分批上传，每个文档只包含用到的数据模型。",
+			},
+			&cli.IntFlag{
+				Name:  flagBatchSize,
+				Value: 0,
+				Usage: "每批最多上传的接口数，0 表示不限制。",
+			},
+			&cli.IntFlag{
+				Name:  flagConcurrency,
+				Value: 1,
+				Usage: "分批上传时的并发数，1 表示按顺序逐批上传。",
+			},
+			&cli.BoolFlag{
+				Name:  flagDryRun,
+				Value: false,
+				Usage: "只预览同步结果，不导入任何数据：与 Apifox 项目中当前的接口比较，列出将新建、修改、不变以及只存在于 Apifox 中的接口。",
+			},
+		}, filterFlags()...),
+		Action: func(c *cli.Context) error {
+			sync, err := newApifoxSync(c)
+			if err != nil {
+				return err
+			}
+			return apifoxImptData(c.Context, sync, c.String(flagDir), c.String(flagOutFile), c.Bool(flagWatch))
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "flags",
+				Usage: "查询相关参数。",
+				Action: func(c *cli.Context) error {
+					client := newApifoxClient(c)
+					log.Info("baseUrl=%s", client.BaseUrl)
+					log.Info("projectId=%s", client.ProjectId)
+					log.Info("accessToken=%s", client.AccessToken)
+					log.Info("proxy=%s", client.Proxy)
+					log.Info("isDebug=%v", log.IsDebug)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// newApifoxClient 根据命令行参数创建 Apifox 开放 API 客户端
+func newApifoxClient(c *cli.Context) *apifox.Client {
+	client := apifox.NewClient(c.String(flagProject), c.String(flagToken))
+	client.BaseUrl = c.String(flagBaseUrl)
+	client.Proxy = c.String(flagProxy)
+	client.Timeout = c.Duration(flagTimeout)
+	client.MaxRetries = c.Int(flagRetries)
+	return client
+}
+
+// apifoxSync 同步到 Apifox 的相关设置
+type apifoxSync struct {
+	client      *apifox.Client
+	options     apifox.ImportOptions
+	split       apifox.SplitOptions
+	concurrency int    // 分批上传时的并发数
+	reportFile  string // 同步结果写入的文件
+	dryRun      bool   // 只预览同步结果，不导入任何数据
+
+	filter *filter.Filter // 筛选要同步的接口
+}
+
+// newApifoxSync 根据命令行参数获取同步设置
+func newApifoxSync(c *cli.Context) (*apifoxSync, error) {
+	f, err := newFilter(c)
+	if err != nil {
+		return nil, err
+	}
+	return &apifoxSync{
+		client: newApifoxClient(c),
+		options: apifox.ImportOptions{
+			ApiOverwriteMode:    c.String(flagApiOverwriteMode),
+			SchemaOverwriteMode: c.String(flagSchemaOverwriteMode),
+			SyncApiFolder:       c.Bool(flagSyncApiFolder),
+		},
+		split: apifox.SplitOptions{
+			ByFolder:      c.Bool(flagSplitByFolder),
+			MaxOperations: c.Int(flagBatchSize),
+		},
+		concurrency: c.Int(flagConcurrency),
+		reportFile:  c.String(flagReport),
+		dryRun:      c.Bool(flagDryRun),
+		filter:      f,
+	}, nil
+}
+
+// 解析 go 注释生成 API 文档，并导入到 apifox。
+//
+//	watch 为 true 时，会持续监听代码目录和解析过的外部包目录，文件变化后只重新解析变化的文件，并重新导出或同步。
+//	不监听时，导出或同步失败、有导入出错的接口或数据模型会返回错误，命令以非 0 状态码退出。
+func apifoxImptData(ctx context.Context, sync *apifoxSync, dir, outFile string, watchMode bool) error {
+	log.StartSpinner(ctx)
+	defer log.StopSpinner()
+
+	goParser, err := scanDir(dir)
+	if err != nil {
+		log.Error(err.Error())
+		return cli.Exit("", 1)
+	}
+	if !watchMode {
+		if !apifoxExport(ctx, sync, goParser, outFile) {
+			return cli.Exit("", 1)
+		}
+		return nil
+	}
+	export := func() {
+		apifoxExport(ctx, sync, goParser, outFile)
+	}
+	export()
+	watchParser(ctx, goParser, export)
+	return nil
+}
+
+// apifoxExport 解析每个文件并生成接口文档，导出到文件或同步到 Apifox，失败或有导入出错时返回 false。
+func apifoxExport(ctx context.Context, sync *apifoxSync, goParser *parser.Parser, outFile string) bool {
+	items, err := goParser.Parse()
+	if err != nil {
+		log.Error(err.Error())
+		return false
+	}
+	if !sync.filter.Empty() {
+		total := len(items)
+		items = sync.filter.Apply(items)
+		log.Info("筛选出%d个接口，共%d个", len(items), total)
+	}
+	api2 := newOpenApi2(items)
+
+	// 导出到文件
+	if outFile != "" {
+		log.Debug(log.UpdateSpinner("导出到文件 %s", outFile))
+
+		api2JsonData, err := json.MarshalIndent(api2, "", "    ")
+		if err != nil {
+			log.Error(err.Error())
+			return false
+		}
+		if err = os.WriteFile(outFile, api2JsonData, fs.ModePerm); err != nil {
+			log.Error(err.Error())
+			return false
+		}
+		log.Success("导出文件成功 %s", outFile)
+		return true
+	}
+
+	if sync.dryRun {
+		preview, err := apifoxPreview(ctx, sync, api2)
+		if err != nil {
+			log.Error(err.Error())
+			return false
+		}
+		writeReport(sync.reportFile, preview)
+		log.Success("预览完成，没有导入任何数据")
+		return true
+	}
+
+	report, _ := apifoxPush(ctx, sync, items, api2)
+	writeReport(sync.reportFile, report)
+	switch {
+	case !report.Success:
+		log.Error("同步 Apifox 失败")
+	case report.HasError():
+		log.Error("同步 Apifox 完成，部分接口或数据模型导入出错")
+	default:
+		log.Success("同步 Apifox 成功")
+	}
+	return !report.HasError()
+}
+
+// apifoxPush 将文档拆分后分批上传到 Apifox，输出并返回同步报告。
+//
+//	上传出错时仍然返回报告，其中包含错误信息。
+func apifoxPush(ctx context.Context, sync *apifoxSync, items []parser.ApiItem, api2 *spec.Swagger) (*apifox.ImportReport, error) {
+	log.Debug(log.UpdateSpinner("同步到 Apifox"))
+
+	docs := apifox.SplitOpenApi2(api2, sync.split)
+	if len(docs) > 1 {
+		log.Info("拆分为%d个文档分批上传", len(docs))
+	}
+	result, err := sync.client.ImportBatches(ctx, docs, sync.options, sync.concurrency)
+	if err != nil {
+		log.Error(err.Error())
+	}
+	report := apifox.NewImportReport(items, result)
+	if err == nil || len(docs) > 1 {
+		var table strings.Builder
+		report.Table(&table)
+		log.Info(strings.TrimSuffix(table.String(), "\n"))
+	}
+	return report, err
+}
+
+// apifoxPreview 与 Apifox 项目中当前的接口比较，输出并返回预览结果，不导入任何数据。
+//
+//	有筛选条件时，只把 Apifox 项目中满足筛选条件的接口当作多余的接口，项目中的其他接口不在本次同步的范围内。
+func apifoxPreview(ctx context.Context, sync *apifoxSync, api2 *spec.Swagger) (*apifox.Preview, error) {
+	log.Debug(log.UpdateSpinner("导出 Apifox 项目中的接口"))
+
+	remote, err := sync.client.ExportOpenApi2(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var inScope func(item *parser.ApiItem) bool
+	if !sync.filter.Empty() {
+		inScope = sync.filter.MatchRemote
+		if sync.filter.HasPackages() {
+			log.Warn("Apifox 项目中的接口没有 Go 包信息，按 Go 包筛选时不检查多余（orphaned）的接口")
+		}
+	}
+	preview := apifox.NewPreview(remote, api2, sync.options.ApiOverwriteMode, inScope)
+
+	var table strings.Builder
+	preview.Table(&table)
+	log.Info(strings.TrimSuffix(table.String(), "\n"))
+	return preview, nil
+}
+
+// writeReport 将同步报告或预览结果以 JSON 格式写入到文件，file 为空时不写入
+func writeReport(file string, report interface{}) {
+	if file == "" {
+		return
+	}
+	data, err := json.MarshalIndent(report, "", "    ")
+	if err == nil {
+		err = os.WriteFile(file, data, fs.ModePerm)
+	}
+	if err != nil {
+		log.Error("写入同步报告出错 %s", err.Error())
+		return
+	}
+	log.Info("写入同步报告 %s", file)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/coverage"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/parser"
+	"regexp"
+)
+
+const (
+	flagReceiver = "receiver"
+	flagMin      = "min"
+)
+
+func coverageCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "coverage",
+		Usage: "统计接口方法的文档覆盖率，列出没有文档或文档不完整的方法，覆盖率低于阈值时返回非零退出码。",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "要统计的 Go 源码文件的目录。",
+			},
+			&cli.StringFlag{
+				Name:  flagReceiver,
+				Usage: "接收者类型名称的正则表达式（如 Handler$），匹配的类型的导出方法都视为接口方法。",
+			},
+			&cli.Float64Flag{
+				Name:  flagMin,
+				Usage: "最低文档覆盖率（0-100），低于该值时返回非零退出码，用于 CI。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var receiver *regexp.Regexp
+			if expr := c.String(flagReceiver); expr != "" {
+				var err error
+				if receiver, err = regexp.Compile(expr); err != nil {
+					return fmt.Errorf("无法解析接收者类型的正则表达式 %s: %s", expr, err)
+				}
+			}
+
+			log.StartSpinner(c.Context)
+			goParser := parser.NewParser()
+			_, err := goParser.Scan(c.String(flagDir))
+			var items []parser.ApiItem
+			if err == nil {
+				items, err = goParser.Parse()
+			}
+			log.StopSpinner()
+			if err != nil {
+				return err
+			}
+
+			report := coverage.Analyze(goParser.Scanner().Files(), items, receiver)
+			fmt.Println(report.Text())
+			if min := c.Float64(flagMin); report.Percent() < min {
+				log.Warn("文档覆盖率 %.1f%% 低于 %.1f%%", report.Percent(), min)
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
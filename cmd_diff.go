@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/diff"
+	"github.com/whaios/apigo/log"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	flagGitRef = "gitRef"
+	flagFormat = "format"
+)
+
+func diffCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "diff",
+		Usage:     "比较两份 API 文档，区分破坏性变更，存在破坏性变更时返回非零退出码。",
+		ArgsUsage: "[old.json] [new.json]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  flagGitRef,
+				Usage: "从指定 git 版本（如 main）检出代码到临时工作区，生成旧文档。",
+			},
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "配合 --gitRef 使用，要解析的 Go 源码文件的目录。没有指定新文档时，也用于生成新文档。",
+			},
+			&cli.StringFlag{
+				Name:    flagFormat,
+				Aliases: []string{"f"},
+				Value:   diff.FormatText,
+				Usage:   "报告格式。枚举值: text, markdown, json",
+			},
+			&cli.StringFlag{
+				Name:    flagOutFile,
+				Aliases: []string{"of"},
+				Usage:   "将报告写入指定文件，不指定则输出到控制台。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			log.StartSpinner(c.Context)
+			report, err := diffDocs(c.String(flagGitRef), c.String(flagDir), c.Args().Slice())
+			log.StopSpinner()
+			if err != nil {
+				return err
+			}
+
+			out, err := report.Format(c.String(flagFormat))
+			if err != nil {
+				return err
+			}
+			if outFile := c.String(flagOutFile); outFile != "" {
+				if err = os.WriteFile(outFile, []byte(out), fs.ModePerm); err != nil {
+					return err
+				}
+				log.Success("导出报告成功 %s", outFile)
+			} else {
+				fmt.Println(out)
+			}
+
+			if report.HasBreaking() {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// diffDocs 比较新旧文档
+//   - 没有指定 gitRef 时，args 为 旧文档 和 新文档 两个文件
+//   - 指定 gitRef 时，旧文档由该版本的 dir 目录生成，新文档为 args 中的文件，没有则由当前代码的 dir 目录生成
+func diffDocs(gitRef, dir string, args []string) (*diff.Report, error) {
+	var oldDoc, newDoc *spec.Swagger
+	var err error
+	if gitRef == "" {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("需要指定旧文档和新文档两个文件，或使用 --%s 参数", flagGitRef)
+		}
+		if oldDoc, err = diff.LoadFile(args[0]); err != nil {
+			return nil, err
+		}
+		args = args[1:]
+	} else {
+		if len(args) > 1 {
+			return nil, fmt.Errorf("使用 --%s 参数时最多只能指定一个新文档", flagGitRef)
+		}
+		if oldDoc, err = gitRefOpenApi2(gitRef, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) == 1 {
+		newDoc, err = diff.LoadFile(args[0])
+	} else {
+		var items, parseErr = parseApiItems(dir)
+		newDoc, err = newOpenApi2(items), parseErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(oldDoc, newDoc), nil
+}
+
+// gitRefOpenApi2 将指定 git 版本检出到临时工作区，并解析其中与 dir 相同位置的目录生成文档。
+func gitRefOpenApi2(ref, dir string) (*spec.Swagger, error) {
+	absDir, err := filepath.Abs(dir)
+	if err == nil {
+		absDir, err = filepath.EvalSymlinks(absDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	topDir, err := execGit(absDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	relDir, err := filepath.Rel(topDir, absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "apigo-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	log.Info("检出 git 版本 %s 到 %s", ref, tmpDir)
+	if _, err = execGit(topDir, "worktree", "add", "--detach", tmpDir, ref); err != nil {
+		return nil, err
+	}
+	defer execGit(topDir, "worktree", "remove", "--force", tmpDir)
+
+	items, err := parseApiItems(filepath.Join(tmpDir, relDir))
+	if err != nil {
+		return nil, err
+	}
+	return newOpenApi2(items), nil
+}
+
+// execGit 在指定目录中执行 git 命令，返回去掉首尾空白的输出内容
+func execGit(dir string, args ...string) (string, error) {
+	var stdout, stderr strings.Builder
+	cmd := exec.Command("git", args...)
+	{
+		cmd.Dir = dir
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("execute git %s, %s, stderr:%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/goclient"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/markdown"
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/postman"
+	"github.com/whaios/apigo/serve"
+	"github.com/whaios/apigo/snippet"
+	"github.com/whaios/apigo/typescript"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	flagTitle     = "title"
+	flagServerUrl = "serverUrl"
+	flagTemplate  = "template"
+	flagClient    = "client"
+	flagPackage   = "package"
+)
+
+// 导出格式
+const (
+	exportPostman  = "postman"
+	exportMarkdown = "markdown"
+	exportHtml     = "html"
+	exportHttp     = "http"
+	exportCurl     = "curl"
+	exportTs       = "typescript"
+	exportGo       = "go"
+)
+
+var exportFormats = []string{exportPostman, exportMarkdown, exportHtml, exportHttp, exportCurl, exportTs, exportGo}
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "快速生成 API 文档，并导出为其他工具或格式的文件。",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "要解析的 Go 源码文件的目录。",
+			},
+			&cli.StringFlag{
+				Name:    flagFormat,
+				Aliases: []string{"f"},
+				Value:   exportPostman,
+				Usage:   "导出格式。枚举值: " + strings.Join(exportFormats, ", "),
+			},
+			&cli.StringFlag{
+				Name:    flagOutFile,
+				Aliases: []string{"of"},
+				Usage:   "导出到指定文件，不指定则输出到控制台。html 格式指定为目录时写入目录中的 index.html；markdown 格式指定为目录时按接口目录拆分为多个文件，并生成索引页 README.md；http 格式必须指定为目录。",
+			},
+			&cli.StringFlag{
+				Name:  flagTitle,
+				Value: "Apigo",
+				Usage: "文档标题，如 Postman 集合的名称。",
+			},
+			&cli.StringFlag{
+				Name:  flagServerUrl,
+				Value: "http://localhost:8080",
+				Usage: "接口服务地址，作为 Postman 集合变量 baseUrl 或 .http 环境文件中 host 的值。",
+			},
+			&cli.StringFlag{
+				Name:  flagTemplate,
+				Usage: "自定义模板文件（Go text/template），用于 markdown 格式，不指定则使用内置模板。",
+			},
+			&cli.StringFlag{
+				Name:  flagClient,
+				Usage: "用于 typescript 格式，同时生成请求客户端，每个接口对应一个函数。枚举值: fetch, axios",
+			},
+			&cli.StringFlag{
+				Name:  flagPackage,
+				Value: goclient.DefaultPackage,
+				Usage: "用于 go 格式，生成代码的包名。",
+			},
+		}, filterFlags()...),
+		Action: func(c *cli.Context) error {
+			f, err := newFilter(c)
+			if err != nil {
+				return err
+			}
+
+			log.StartSpinner(c.Context)
+			items, err := parseApiItems(c.String(flagDir))
+			log.StopSpinner()
+			if err != nil {
+				return err
+			}
+			if !f.Empty() {
+				total := len(items)
+				items = f.Apply(items)
+				log.Info("筛选出%d个接口，共%d个", len(items), total)
+			}
+
+			if c.String(flagFormat) == exportHttp {
+				return exportHttpFiles(c.String(flagOutFile), c.String(flagTitle), c.String(flagServerUrl), items)
+			}
+			if outFile := c.String(flagOutFile); c.String(flagFormat) == exportMarkdown && outFile != "" {
+				if info, err := os.Stat(outFile); err == nil && info.IsDir() {
+					return exportMarkdownPages(outFile, c.String(flagTemplate), c.String(flagTitle), items)
+				}
+			}
+			data, err := exportDoc(c, items)
+			if err != nil {
+				return err
+			}
+			if outFile := c.String(flagOutFile); outFile != "" {
+				if info, err := os.Stat(outFile); err == nil && info.IsDir() && c.String(flagFormat) == exportHtml {
+					outFile = filepath.Join(outFile, "index.html")
+				}
+				if err = os.WriteFile(outFile, data, fs.ModePerm); err != nil {
+					return err
+				}
+				log.Success("导出文件成功 %s", outFile)
+			} else {
+				fmt.Println(string(data))
+			}
+			return nil
+		},
+	}
+}
+
+// exportDoc 将接口文档转换为指定格式
+func exportDoc(c *cli.Context, items []parser.ApiItem) ([]byte, error) {
+	switch format := c.String(flagFormat); format {
+	case exportPostman:
+		return postman.NewCollection(c.String(flagTitle), c.String(flagServerUrl), items).JSON()
+	case exportMarkdown:
+		tmpl, err := readTemplate(c.String(flagTemplate))
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		err = markdown.Render(&buf, tmpl, markdown.NewDocument(c.String(flagTitle), items))
+		return buf.Bytes(), err
+	case exportHtml:
+		api2 := newOpenApi2(items)
+		api2.Info.Title = c.String(flagTitle)
+		data, err := json.Marshal(api2)
+		if err != nil {
+			return nil, err
+		}
+		return serve.StaticHTML(data), nil
+	case exportCurl:
+		return []byte(snippet.CurlScript(newRequests(items))), nil
+	case exportTs:
+		ts, err := typescript.Generate(items, c.String(flagClient))
+		return []byte(ts), err
+	case exportGo:
+		return goclient.Generate(c.String(flagPackage), items)
+	default:
+		return nil, fmt.Errorf("不支持的导出格式 %q，可选值: %s", format, strings.Join(exportFormats, ", "))
+	}
+}
+
+// readTemplate 读取自定义模板文件，没有指定时返回空字符串
+func readTemplate(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("读取模板文件出错: %s", err)
+	}
+	return string(data), nil
+}
+
+func newRequests(items []parser.ApiItem) []*snippet.Request {
+	reqs := make([]*snippet.Request, 0, len(items))
+	for i := range items {
+		reqs = append(reqs, snippet.NewRequest(&items[i]))
+	}
+	return reqs
+}
+
+// exportMarkdownPages 在 outDir 目录中按接口目录生成 Markdown 文件，以及包含各文件链接的索引页
+func exportMarkdownPages(outDir, tmplFile, title string, items []parser.ApiItem) error {
+	tmpl, err := readTemplate(tmplFile)
+	if err != nil {
+		return err
+	}
+	for _, page := range markdown.Pages(markdown.NewDocument(title, items)) {
+		var buf bytes.Buffer
+		if err = markdown.Render(&buf, tmpl, page.Doc); err != nil {
+			return err
+		}
+		p := filepath.Join(outDir, filepath.FromSlash(page.Path))
+		if err = os.MkdirAll(filepath.Dir(p), fs.ModePerm); err != nil {
+			return err
+		}
+		if err = os.WriteFile(p, buf.Bytes(), fs.ModePerm); err != nil {
+			return err
+		}
+		log.Info("导出文件 %s", p)
+	}
+	log.Success("导出文件成功 %s", outDir)
+	return nil
+}
+
+// exportHttpFiles 在 outDir 目录中按接口目录生成 .http 文件，以及 JetBrains HTTP Client 的环境文件
+func exportHttpFiles(outDir, title, host string, items []parser.ApiItem) error {
+	if outDir == "" {
+		return fmt.Errorf("http 格式需要使用 --%s 指定导出目录", flagOutFile)
+	}
+	reqs := newRequests(items)
+	for _, file := range snippet.HttpFiles(reqs, title) {
+		p := filepath.Join(outDir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(p), fs.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(file.String()), fs.ModePerm); err != nil {
+			return err
+		}
+		log.Info("导出文件 %s", p)
+	}
+
+	// 已有的环境文件中可能填写了令牌等变量的值，不覆盖
+	envFile := filepath.Join(outDir, snippet.EnvFile)
+	if _, err := os.Stat(envFile); err == nil {
+		log.Warn("环境文件已存在，不覆盖 %s", envFile)
+	} else {
+		data, err := snippet.NewEnvFile(host, snippet.Variables(reqs))
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(envFile, data, fs.ModePerm); err != nil {
+			return err
+		}
+	}
+	log.Success("导出文件成功 %s", outDir)
+	return nil
+}
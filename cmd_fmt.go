@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/commentfmt"
+	"github.com/whaios/apigo/log"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const flagCheck = "check"
+
+func fmtCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "fmt",
+		Usage: "格式化代码中的 apigo 注释：注释名称转为小写、按固定顺序排列并对齐。",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "要格式化的 Go 源码文件的目录，包括子目录。",
+			},
+			&cli.BoolFlag{
+				Name:  flagCheck,
+				Usage: "只检查，输出需要格式化的文件，存在时返回非零退出码，用于 CI。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			files, err := formatFiles(c.String(flagDir), !c.Bool(flagCheck))
+			if err != nil {
+				return err
+			}
+			if c.Bool(flagCheck) {
+				for _, file := range files {
+					fmt.Println(file)
+				}
+				if len(files) > 0 {
+					return cli.Exit("", 1)
+				}
+				return nil
+			}
+			for _, file := range files {
+				log.Success("格式化 %s", file)
+			}
+			return nil
+		},
+	}
+}
+
+// formatFiles 格式化目录中所有 Go 代码文件的 apigo 注释，返回需要格式化的文件，write 为 false 时不修改文件。
+//
+//	跳过 vendor、testdata 和 . 开头的目录，以及测试文件。
+func formatFiles(dir string, write bool) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out, err := commentfmt.Source(src)
+		if err != nil {
+			return fmt.Errorf("解析文件 %s 出错: %s", path, err)
+		}
+		if bytes.Equal(src, out) {
+			return nil
+		}
+		files = append(files, path)
+		if write {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, out, info.Mode())
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/migrate"
+)
+
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "将其他工具的接口注释转换为 apigo 的注释格式。",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "swag",
+				Usage: "将 swaggo/swag 的注释（如 @Summary、@Router、@Param、@Success）原地改写为 apigo 的注释。",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    flagDir,
+						Aliases: []string{"d"},
+						Value:   ".",
+						Usage:   "要转换的 Go 源码文件的目录。",
+					},
+					&cli.BoolFlag{
+						Name:  flagDryRun,
+						Usage: "只输出修改内容（diff 格式），不修改代码文件。",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					log.StartSpinner(c.Context)
+					scanner := goscanner.New()
+					err := scanner.Scan(c.String(flagDir))
+					log.StopSpinner()
+					if err != nil {
+						return err
+					}
+
+					result := migrate.Swag(scanner.Files())
+					if warnings := result.Warnings(); warnings != "" {
+						log.Warn("以下注释无法转换，需要手动修改:\n%s", warnings)
+					}
+					if c.Bool(flagDryRun) {
+						fmt.Print(result.Diff())
+						return nil
+					}
+					if result.Changed() == 0 {
+						log.Info("没有需要转换的注释")
+						return nil
+					}
+					if err = result.Write(); err != nil {
+						return err
+					}
+					log.Success("转换注释成功，共修改 %d 行注释", result.Changed())
+					return nil
+				},
+			},
+		},
+	}
+}
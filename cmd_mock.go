@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/mock"
+	"net/http"
+	"strings"
+)
+
+const (
+	flagHost = "host"
+	flagPort = "port"
+)
+
+func mockCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "mock",
+		Usage: "根据接口文档启动模拟服务，返回示例响应数据。",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "要解析的 Go 源码文件的目录，该目录下必须有 Go 源码文件。",
+			},
+			&cli.StringFlag{
+				Name:  flagHost,
+				Value: "127.0.0.1",
+				Usage: "服务监听的主机地址。",
+			},
+			&cli.IntFlag{
+				Name:    flagPort,
+				Aliases: []string{"p"},
+				Value:   4010,
+				Usage:   "服务监听的端口。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			items, err := parseApiItems(c.String(flagDir))
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				log.Debug("注册接口 %s %s", strings.ToUpper(item.Method), item.Path)
+			}
+
+			addr := fmt.Sprintf("%s:%d", c.String(flagHost), c.Int(flagPort))
+			log.Success("模拟服务已启动 http://%s ，共%d个接口", addr, len(items))
+			log.Info("使用请求头 %s 或查询参数 %s 指定返回的响应状态码", mock.HeaderStatus, mock.QueryStatus)
+			return http.ListenAndServe(addr, mock.New(items))
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/serve"
+	"net/http"
+)
+
+const flagAddr = "addr"
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "启动本地文档服务，离线预览生成的 API 文档。",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Value:   ".",
+				Usage:   "要解析的 Go 源码文件的目录，该目录下必须有 Go 源码文件。",
+			},
+			&cli.StringFlag{
+				Name:    flagAddr,
+				Aliases: []string{"a"},
+				Value:   "127.0.0.1:8080",
+				Usage:   "服务监听地址。",
+			},
+			&cli.BoolFlag{
+				Name:    flagWatch,
+				Aliases: []string{"w"},
+				Value:   true,
+				Usage:   "监听代码文件变化，重新解析后页面自动刷新。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			goParser, err := scanDir(c.String(flagDir))
+			if err != nil {
+				return err
+			}
+
+			srv := serve.New()
+			if err = serveUpdate(srv, goParser); err != nil {
+				return err
+			}
+			if c.Bool(flagWatch) {
+				go watchParser(c.Context, goParser, func() {
+					if err := serveUpdate(srv, goParser); err != nil {
+						log.Error(err.Error())
+					}
+				})
+			}
+
+			addr := c.String(flagAddr)
+			log.Success("文档服务已启动 http://%s ，OpenAPI 文档 http://%s%s", addr, addr, serve.PathOpenApi)
+			return http.ListenAndServe(addr, srv)
+		},
+	}
+}
+
+// serveUpdate 解析接口文档，并更新文档服务提供的内容
+func serveUpdate(srv *serve.Server, goParser *parser.Parser) error {
+	items, err := goParser.Parse()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(newOpenApi2(items))
+	if err != nil {
+		return err
+	}
+	srv.SetSpec(data)
+	return nil
+}
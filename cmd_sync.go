@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/urfave/cli/v2"
+	"github.com/whaios/apigo/apifox"
+	"github.com/whaios/apigo/config"
+	"github.com/whaios/apigo/filter"
+	"github.com/whaios/apigo/log"
+	"github.com/whaios/apigo/parser"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	flagConfig = "config"
+	flagTarget = "target"
+)
+
+func syncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "按配置文件中的同步目标，只解析一次代码，将筛选出的接口分别同步到多个 Apifox 项目。",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagConfig,
+				Aliases: []string{"c"},
+				Value:   config.DefaultFile,
+				Usage:   "配置文件，其中接口状态的生命周期规则（statusRules）只在 sync 时检查，其他命令不检查",
+			},
+			&cli.StringSliceFlag{
+				Name:    flagTarget,
+				Aliases: []string{"T"},
+				Usage:   "只同步指定名称的目标，可以指定多次，默认同步所有目标",
+			},
+			&cli.StringFlag{
+				Name:    flagDir,
+				Aliases: []string{"d"},
+				Usage:   "要解析的 Go 源码文件的目录，默认使用配置文件中的 dir",
+			},
+			&cli.BoolFlag{
+				Name:  flagDryRun,
+				Value: false,
+				Usage: "只预览每个目标的同步结果，不导入任何数据。",
+			},
+			&cli.StringFlag{
+				Name:  flagReport,
+				Value: "",
+				Usage: "将每个目标的同步结果以 JSON 格式写入到指定文件，可作为 CI 的构建产物。",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Load(c.String(flagConfig))
+			if err != nil {
+				return err
+			}
+			targets := cfg.Targets
+			if names := c.StringSlice(flagTarget); len(names) > 0 {
+				targets = make([]config.Target, 0, len(names))
+				for _, name := range names {
+					t := cfg.GetTarget(name)
+					if t == nil {
+						return fmt.Errorf("配置文件中没有同步目标 %s", name)
+					}
+					targets = append(targets, *t)
+				}
+			}
+			dir := c.String(flagDir)
+			if dir == "" {
+				dir = cfg.Dir
+			}
+			return syncTargets(c.Context, cfg.StatusRules, targets, dir, c.Bool(flagDryRun), c.String(flagReport))
+		},
+	}
+}
+
+// syncResult 单个目标的同步结果
+type syncResult struct {
+	Target    string               `json:"target"`            // 目标名称
+	ProjectId string               `json:"projectId"`         // 项目 ID
+	Count     int                  `json:"count"`             // 筛选出的接口数
+	Error     string               `json:"error,omitempty"`   // 同步出错的原因
+	Report    *apifox.ImportReport `json:"report,omitempty"`  // 同步报告
+	Preview   *apifox.Preview      `json:"preview,omitempty"` // 预览结果
+}
+
+// 同步是否出错
+func (r *syncResult) failed() bool {
+	return r.Error != "" || (r.Report != nil && r.Report.HasError())
+}
+
+// newTargetSync 根据同步目标的配置获取同步设置，没有配置的项使用默认值
+func newTargetSync(t *config.Target, dryRun bool) (*apifoxSync, error) {
+	f, err := filter.New(t.Filter)
+	if err != nil {
+		return nil, err
+	}
+	tokenEnv := t.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = EnvApifoxAccessToken
+	}
+	client := apifox.NewClient(t.GetProjectId(), os.Getenv(tokenEnv))
+	if t.BaseUrl != "" {
+		client.BaseUrl = t.BaseUrl
+	}
+	client.Proxy = t.Proxy
+	if timeout := t.GetTimeout(); timeout > 0 {
+		client.Timeout = timeout
+	}
+	if t.Retries != nil {
+		client.MaxRetries = *t.Retries
+	}
+
+	apiOverwriteMode := t.ApiOverwriteMode
+	if apiOverwriteMode == "" {
+		apiOverwriteMode = "methodAndPath"
+	}
+	return &apifoxSync{
+		client: client,
+		options: apifox.ImportOptions{
+			ApiOverwriteMode:    apiOverwriteMode,
+			SchemaOverwriteMode: t.SchemaOverwriteMode,
+			SyncApiFolder:       t.SyncApiFolder,
+		},
+		split: apifox.SplitOptions{
+			ByFolder:      t.SplitByFolder,
+			MaxOperations: t.BatchSize,
+		},
+		concurrency: t.Concurrency,
+		dryRun:      dryRun,
+		filter:      f,
+	}, nil
+}
+
+// syncTargets 解析一次代码，将筛选出的接口依次同步到每个目标，有目标同步出错时返回错误码 1。
+//
+//	接口不符合状态的生命周期规则时，不同步任何目标，并返回错误码 1。
+func syncTargets(ctx context.Context, rules []parser.StatusRule, targets []config.Target, dir string, dryRun bool, reportFile string) error {
+	log.StartSpinner(ctx)
+	defer log.StopSpinner()
+
+	items, err := parseApiItems(dir)
+	if err != nil {
+		return err
+	}
+	if errs := parser.CheckStatusRules(items, rules); len(errs) > 0 {
+		for _, err := range errs {
+			log.Error(err.Error())
+		}
+		log.Error("有%d个接口不符合状态的生命周期规则", len(errs))
+		return cli.Exit("", 1)
+	}
+
+	results := make([]*syncResult, 0, len(targets))
+	for i := range targets {
+		result := syncTarget(ctx, &targets[i], items, dryRun)
+		results = append(results, result)
+	}
+	writeReport(reportFile, results)
+
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "目标\t项目\t接口数\t结果")
+	failed := false
+	for _, result := range results {
+		status := "成功"
+		switch {
+		case result.Error != "":
+			status = "失败: " + result.Error
+		case result.Report != nil && !result.Report.Success:
+			status = "失败: " + result.Report.ErrorMessage
+		case result.Report != nil && result.Report.HasError():
+			status = "部分出错"
+		case result.Preview != nil:
+			status = "已预览"
+		}
+		failed = failed || result.failed()
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", result.Target, result.ProjectId, result.Count, status)
+	}
+	_ = tw.Flush()
+	log.Info(strings.TrimSuffix(table.String(), "\n"))
+
+	if failed {
+		log.Error("部分目标同步失败")
+		return cli.Exit("", 1)
+	}
+	log.Success("同步完成")
+	return nil
+}
+
+// syncTarget 将筛选出的接口同步到单个目标
+func syncTarget(ctx context.Context, t *config.Target, items []parser.ApiItem, dryRun bool) *syncResult {
+	result := &syncResult{Target: t.Name, ProjectId: t.GetProjectId()}
+	log.Info("同步目标 %s（项目 %s）", t.Name, result.ProjectId)
+
+	sync, err := newTargetSync(t, dryRun)
+	if err != nil {
+		result.Error = err.Error()
+		log.Error(result.Error)
+		return result
+	}
+	items = sync.filter.Apply(items)
+	result.Count = len(items)
+	log.Info("筛选出%d个接口", len(items))
+
+	api2 := newOpenApi2(items)
+	if dryRun {
+		// 多个目标同步到同一项目时，只检查本目标筛选范围内的多余接口
+		if result.Preview, err = apifoxPreview(ctx, sync, api2); err != nil {
+			result.Error = err.Error()
+			log.Error(result.Error)
+		}
+		return result
+	}
+	result.Report, err = apifoxPush(ctx, sync, items, api2)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,251 @@
+// Package commentfmt 格式化 Go 代码中的 apigo 注释。
+//
+//   - 注释名称转为小写，如：@Param -> @param
+//   - 按固定的顺序排列注释，同名的注释保持原有顺序
+//   - 对齐注释的值，以及 @param、@resp 中的每一列
+//   - @param 的示例值和备注、@resp 的名称统一使用双引号
+//
+// 只格式化包含 @url 的方法注释，以及类型上包含 apigo 注释的通用注释，其他注释保持不变。
+package commentfmt
+
+import (
+	"fmt"
+	"github.com/whaios/apigo/parser"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagOrder 注释的排列顺序
+var tagOrder = []string{
+	parser.TagTitle, parser.TagFolder, parser.TagTag, parser.TagStatus, parser.TagDepends,
+	parser.TagDesc, parser.TagRemark, parser.TagUrl, parser.TagBodyType, parser.TagParam,
+	parser.TagContentType, parser.TagSuccess, parser.TagResp,
+}
+
+// @param 结构体，如：body model.Pet{}
+var paramStructPattern = regexp.MustCompile(`^(\w+)\s+(\S+{})$`)
+
+// @param 单个参数，示例值和备注可以不使用引号（不能包含空格）
+var paramPattern = regexp.MustCompile(`^(\w+)\s+(\S+)\s+(\S+)\s+(\w+)\s+("[^"]*"|\S+)\s+("[^"]*"|\S+)$`)
+
+// @resp 状态码 名称 结构体
+var respPattern = regexp.MustCompile(`^(\d+)\s+("[^"]*"|\S+)\s+(\S.*)$`)
+
+// tagLine 一行 apigo 注释
+type tagLine struct {
+	comment *ast.Comment
+	tag     string   // 小写的注释名称
+	columns []string // 注释的值，@param、@resp 拆分为多列，其他注释只有一列
+	order   int
+}
+
+// Source 格式化 Go 源码中的 apigo 注释，没有需要格式化的注释时返回原内容
+func Source(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, goparser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	type replacement struct {
+		offset, end int
+		text        string
+	}
+	replacements := make([]replacement, 0)
+	for _, decl := range file.Decls {
+		var doc *ast.CommentGroup
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil && hasTag(d.Doc, parser.TagUrl) {
+				doc = d.Doc
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil && hasTag(d.Doc, "") {
+				doc = d.Doc
+			}
+		}
+		if doc == nil || hasTag(doc, "@router") {
+			// 没有 apigo 注释，或者是 swag 注释
+			continue
+		}
+
+		lines := tagLines(doc)
+		for i, text := range formatLines(lines) {
+			if text != lines[i].comment.Text {
+				replacements = append(replacements, replacement{
+					offset: fset.Position(lines[i].comment.Pos()).Offset,
+					end:    fset.Position(lines[i].comment.End()).Offset,
+					text:   text,
+				})
+			}
+		}
+	}
+	if len(replacements) == 0 {
+		return src, nil
+	}
+
+	out := make([]byte, 0, len(src))
+	last := 0
+	for _, r := range replacements {
+		out = append(out, src[last:r.offset]...)
+		out = append(out, r.text...)
+		last = r.end
+	}
+	return append(out, src[last:]...), nil
+}
+
+// hasTag 注释中是否有指定的注释，tag 为空时检查是否有任意 apigo 注释
+func hasTag(doc *ast.CommentGroup, tag string) bool {
+	for _, comment := range doc.List {
+		name := tagName(comment.Text)
+		if (tag == "" && orderOf(name) >= 0) || (tag != "" && name == tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName 小写的注释名称，不是 @ 开头的注释返回空字符串
+func tagName(text string) string {
+	if !strings.HasPrefix(text, "//") {
+		return ""
+	}
+	fields := strings.Fields(text[2:])
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "@") {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+func orderOf(tag string) int {
+	for i, t := range tagOrder {
+		if t == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// tagLines 注释块中的 apigo 注释，按原有顺序排列
+func tagLines(doc *ast.CommentGroup) []*tagLine {
+	lines := make([]*tagLine, 0)
+	for _, comment := range doc.List {
+		tag := tagName(comment.Text)
+		order := orderOf(tag)
+		if order < 0 {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimSpace(comment.Text[2:])[len(tag):])
+		line := &tagLine{comment: comment, tag: tag, columns: []string{value}, order: order}
+		switch tag {
+		case parser.TagUrl, parser.TagDepends, parser.TagStatus, parser.TagBodyType, parser.TagContentType, parser.TagSuccess:
+			line.columns = []string{strings.Join(strings.Fields(value), " ")}
+		case parser.TagParam:
+			if m := paramPattern.FindStringSubmatch(value); m != nil {
+				line.columns = []string{m[1], m[2], m[3], m[4], quote(m[5]), quote(m[6])}
+			} else if m = paramStructPattern.FindStringSubmatch(value); m != nil {
+				line.columns = []string{m[1], m[2]}
+			}
+		case parser.TagResp:
+			if m := respPattern.FindStringSubmatch(value); m != nil {
+				line.columns = []string{m[1], quote(m[2]), m[3]}
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatLines 格式化后的注释，与 lines 中的位置一一对应
+//
+//	排序后的注释依次放回原来 apigo 注释所在的行，其他注释的位置不变。
+func formatLines(lines []*tagLine) []string {
+	sorted := make([]*tagLine, len(lines))
+	copy(sorted, lines)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].order < sorted[j].order
+	})
+
+	tagWidth := 0
+	for _, line := range sorted {
+		if len(line.tag) > tagWidth {
+			tagWidth = len(line.tag)
+		}
+	}
+	// 同一注释中列数相同的行对齐
+	widths := make(map[string][]int)
+	for _, line := range sorted {
+		if len(line.columns) < 2 {
+			continue
+		}
+		key := columnsKey(line)
+		w := widths[key]
+		if w == nil {
+			w = make([]int, len(line.columns))
+			widths[key] = w
+		}
+		for i, col := range line.columns {
+			if cw := width(col); cw > w[i] {
+				w[i] = cw
+			}
+		}
+	}
+
+	texts := make([]string, len(sorted))
+	for i, line := range sorted {
+		var sb strings.Builder
+		sb.WriteString("// " + pad(line.tag, tagWidth) + " ")
+		w := widths[columnsKey(line)]
+		for j, col := range line.columns {
+			if j > 0 {
+				sb.WriteString(" ")
+			}
+			if w != nil && j < len(line.columns)-1 {
+				col = pad(col, w[j])
+			}
+			sb.WriteString(col)
+		}
+		texts[i] = strings.TrimRight(sb.String(), " ")
+	}
+	return texts
+}
+
+// columnsKey 注释名称和列数
+func columnsKey(line *tagLine) string {
+	return fmt.Sprintf("%s/%d", line.tag, len(line.columns))
+}
+
+// quote 使用双引号包裹
+func quote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s
+	}
+	return `"` + s + `"`
+}
+
+// pad 在右侧补充空格到指定的显示宽度
+func pad(s string, w int) string {
+	if n := w - width(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// width 显示宽度，中日韩文字和全角字符的宽度为 2
+func width(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0xA4CF, r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F, r >= 0xFF00 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+package commentfmt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+const handlerGo = `package handler
+
+// ListPet 宠物列表
+//
+// 其他说明保持不变
+//@Resp 200 成功 model.Pet{}
+// @URL   GET    /pet
+//   @param query   status string false available "状态"
+// @param header Authorization string true "bearer {{TOKEN}}" 令牌
+// @folder 宠物
+func ListPet() {}
+
+// @title   通用注释
+// @param header  token string  true  "" ""
+type Common struct{}
+
+// GetPet swag 注释不格式化
+// @Param petId path int true "宠物 id"
+// @Router /pet/{petId} [get]
+func GetPet() {}
+
+// NoUrl 没有 @url 的方法不格式化
+// @title  没有接口
+func NoUrl() {}
+`
+
+func TestSource(t *testing.T) {
+	Convey("测试格式化 apigo 注释", t, func() {
+		out, err := Source([]byte(handlerGo))
+		So(err, ShouldBeNil)
+		So(string(out), ShouldContainSubstring, `// ListPet 宠物列表
+//
+// 其他说明保持不变
+// @folder 宠物
+// @url    GET /pet
+// @param  query  status        string false "available"        "状态"
+// @param  header Authorization string true  "bearer {{TOKEN}}" "令牌"
+// @resp   200 "成功" model.Pet{}
+func ListPet() {}
+`)
+		So(string(out), ShouldContainSubstring, `// @title 通用注释
+// @param header token string true "" ""
+type Common struct{}
+`)
+		So(string(out), ShouldContainSubstring, "// @Param petId path int true \"宠物 id\"\n// @Router /pet/{petId} [get]\n")
+		So(string(out), ShouldContainSubstring, "// @title  没有接口\n")
+
+		Convey("重复格式化不再修改", func() {
+			again, err := Source(out)
+			So(err, ShouldBeNil)
+			So(string(again), ShouldEqual, string(out))
+		})
+	})
+
+	Convey("测试中文宽度对齐", t, func() {
+		out, err := Source([]byte(`package handler
+
+// @url POST /pet
+// @param form name string true "小猫" "宠物名"
+// @param form status string true "sold" "宠物销售状态"
+func AddPet() {}
+`))
+		So(err, ShouldBeNil)
+		So(string(out), ShouldContainSubstring, `// @url   POST /pet
+// @param form name   string true "小猫" "宠物名"
+// @param form status string true "sold" "宠物销售状态"
+`)
+	})
+}
@@ -0,0 +1,134 @@
+// Package config 读取 apigo 的 JSON 配置文件。
+//
+// 配置文件示例（apigo.json）：
+//
+//	{
+//	    "dir": "./example/petshop",
+//	    "targets": [
+//	        {
+//	            "name": "internal",
+//	            "projectId": "123456",
+//	            "tokenEnv": "ApifoxAccessToken",
+//	            "apiOverwriteMode": "methodAndPath"
+//	        },
+//	        {
+//	            "name": "partner",
+//	            "projectIdEnv": "ApifoxPartnerProjectId",
+//	            "tokenEnv": "ApifoxPartnerAccessToken",
+//	            "filter": {"folders": ["宠物商城/**"], "excludeTags": ["internal"]}
+//	        }
+//	    ]
+//	}
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whaios/apigo/filter"
+	"github.com/whaios/apigo/parser"
+	"os"
+	"time"
+)
+
+// DefaultFile 默认的配置文件
+const DefaultFile = "apigo.json"
+
+// Config 配置
+type Config struct {
+	Dir     string   `json:"dir"`     // 要解析的 Go 源码文件的目录
+	Targets []Target `json:"targets"` // 同步目标，按顺序同步
+
+	StatusRules []parser.StatusRule `json:"statusRules"` // 接口状态的生命周期规则，只在 apigo sync 时检查，不符合规则时同步失败
+}
+
+// Target 同步目标，对应一个 Apifox 项目
+type Target struct {
+	Name string `json:"name"` // 必填，目标名称
+
+	ProjectId    string `json:"projectId"`    // 项目 ID
+	ProjectIdEnv string `json:"projectIdEnv"` // 从该环境变量中读取项目 ID，projectId 为空时使用
+	TokenEnv     string `json:"tokenEnv"`     // 从该环境变量中读取个人访问令牌，令牌不应写在配置文件中
+
+	BaseUrl string `json:"baseUrl"` // 开放接口地址
+	Proxy   string `json:"proxy"`   // HTTP 代理地址
+	Timeout string `json:"timeout"` // 单次请求的超时时间，如：60s
+	Retries *int   `json:"retries"` // 最大重试次数
+
+	ApiOverwriteMode    string `json:"apiOverwriteMode"`    // 匹配到相同接口时的覆盖模式
+	SchemaOverwriteMode string `json:"schemaOverwriteMode"` // 匹配到相同数据模型时的覆盖模式
+	SyncApiFolder       bool   `json:"syncApiFolder"`       // 是否同步更新接口所在目录
+
+	SplitByFolder bool `json:"splitByFolder"` // 按一级目录拆分为多个文档分批上传
+	BatchSize     int  `json:"batchSize"`     // 每批最多上传的接口数
+	Concurrency   int  `json:"concurrency"`   // 分批上传时的并发数
+
+	Filter filter.Options `json:"filter"` // 筛选要同步到该目标的接口
+}
+
+// GetProjectId 获取项目 ID
+func (t *Target) GetProjectId() string {
+	if t.ProjectId == "" && t.ProjectIdEnv != "" {
+		return os.Getenv(t.ProjectIdEnv)
+	}
+	return t.ProjectId
+}
+
+// GetTimeout 获取超时时间，没有配置时返回 0
+func (t *Target) GetTimeout() time.Duration {
+	timeout, _ := time.ParseDuration(t.Timeout)
+	return timeout
+}
+
+// Load 读取配置文件
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件出错: %s", err)
+	}
+	cfg := &Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件出错 %s: %s", file, err)
+	}
+	if err = cfg.validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 %s 错误: %s", file, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("没有配置同步目标 targets")
+	}
+	names := make(map[string]bool)
+	for _, t := range c.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("同步目标的 name 不能为空")
+		}
+		if names[t.Name] {
+			return fmt.Errorf("同步目标 %s 重复", t.Name)
+		}
+		names[t.Name] = true
+		if t.ProjectId == "" && t.ProjectIdEnv == "" {
+			return fmt.Errorf("同步目标 %s 没有指定 projectId 或 projectIdEnv", t.Name)
+		}
+		if t.Timeout != "" {
+			if _, err := time.ParseDuration(t.Timeout); err != nil {
+				return fmt.Errorf("同步目标 %s 的 timeout 格式错误: %s", t.Name, err)
+			}
+		}
+		if _, err := filter.New(t.Filter); err != nil {
+			return fmt.Errorf("同步目标 %s 的 filter 错误: %s", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// GetTarget 获取指定名称的同步目标，没有找到返回 nil
+func (c *Config) GetTarget(name string) *Target {
+	for i := range c.Targets {
+		if c.Targets[i].Name == name {
+			return &c.Targets[i]
+		}
+	}
+	return nil
+}
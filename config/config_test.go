@@ -0,0 +1,71 @@
+package config
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	Convey("测试读取配置文件", t, func() {
+		t.Setenv("TEST_PARTNER_PROJECT_ID", "654321")
+		file := writeConfig(t, `{
+			"dir": "./example/petshop",
+			"statusRules": [{"status": "已发布", "disallowDepends": ["designing"]}],
+			"targets": [
+				{"name": "internal", "projectId": "123456", "timeout": "2m"},
+				{"name": "partner", "projectIdEnv": "TEST_PARTNER_PROJECT_ID", "tokenEnv": "TEST_PARTNER_TOKEN",
+				 "filter": {"folders": ["宠物商城/**"], "excludeTags": ["internal"]}}
+			]
+		}`)
+		cfg, err := Load(file)
+		So(err, ShouldBeNil)
+		So(cfg.Dir, ShouldEqual, "./example/petshop")
+		So(cfg.Targets, ShouldHaveLength, 2)
+		So(cfg.StatusRules, ShouldResemble, []parser.StatusRule{
+			{Status: parser.StatusReleased, DisallowDepends: []parser.Status{parser.StatusDesigning}},
+		})
+
+		internal := cfg.GetTarget("internal")
+		So(internal.GetProjectId(), ShouldEqual, "123456")
+		So(internal.GetTimeout(), ShouldEqual, 2*time.Minute)
+		So(internal.Retries, ShouldBeNil)
+
+		partner := cfg.GetTarget("partner")
+		So(partner.GetProjectId(), ShouldEqual, "654321")
+		So(partner.GetTimeout(), ShouldEqual, 0)
+		So(partner.Filter.Folders, ShouldResemble, []string{"宠物商城/**"})
+		So(partner.Filter.ExcludeTags, ShouldResemble, []string{"internal"})
+
+		So(cfg.GetTarget("other"), ShouldBeNil)
+	})
+
+	Convey("测试错误的配置文件", t, func() {
+		_, err := Load(filepath.Join(t.TempDir(), DefaultFile))
+		So(err, ShouldNotBeNil)
+
+		for content, msg := range map[string]string{
+			`{"targets": []}`:                   "没有配置同步目标",
+			`{"targets": [{"projectId": "1"}]}`: "name 不能为空",
+			`{"targets": [{"name": "a", "projectId": "1"}, {"name": "a", "projectId": "2"}]}`: "重复",
+			`{"targets": [{"name": "a"}]}`:                                               "没有指定 projectId",
+			`{"targets": [{"name": "a", "projectId": "1", "timeout": "1x"}]}`:            "timeout 格式错误",
+			`{"targets": [{"name": "a", "projectId": "1", "filter": {"paths": ["("]}}]}`: "filter 错误",
+		} {
+			_, err = Load(writeConfig(t, content))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, msg)
+		}
+	})
+}
@@ -0,0 +1,428 @@
+// Package coverage 统计 Go 代码中接口方法的文档覆盖率。
+//
+// 以下方法视为接口方法：
+//   - 已经生成接口文档的方法
+//   - 注释中有 @url 或 @title，但不是有效接口文档的方法
+//   - 接收者类型名称匹配指定正则表达式的导出方法
+//   - 在路由中注册的方法，如：r.GET("/pet", h.GetPet)、http.HandleFunc("/pet", GetPet)，只取路由的最后一个参数
+package coverage
+
+import (
+	"fmt"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/parser"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 注册路由的方法名称，不区分大小写
+var routeFuncs = map[string]bool{
+	"get": true, "post": true, "put": true, "delete": true, "patch": true, "head": true, "options": true,
+	"any": true, "handle": true, "handlefunc": true, "match": true,
+}
+
+// 单个参数，如：query status string true "" "宠物销售状态"
+var paramPattern = regexp.MustCompile(`^(\w+)\s+(\S+)\s+(\S+)\s+(\w+)\s+"([^"]*)"\s+"([^"]*)"`)
+
+// Handler 接口方法
+type Handler struct {
+	PkgId      string
+	File       string
+	Line       int
+	Func       string   // 方法名称，有接收者时为 Type.Func
+	Endpoint   string   // 接口标识，如：GET /pet/{petId}，没有文档时为空
+	Documented bool     // 是否有效的接口文档
+	Issues     []string // 缺少的注释
+}
+
+// Package 包的文档覆盖率
+type Package struct {
+	PkgId      string
+	Handlers   int // 接口方法个数
+	Documented int // 有接口文档的方法个数
+}
+
+// Percent 文档覆盖率，没有接口方法时为 100
+func (p *Package) Percent() float64 {
+	return percent(p.Documented, p.Handlers)
+}
+
+// Report 文档覆盖率报告
+type Report struct {
+	Handlers []*Handler // 按文件和行号排序
+	Packages []*Package // 按包名排序
+}
+
+// Analyze 统计 files 中接口方法的文档覆盖率，items 为 files 解析出的接口文档，receiver 为空时不按接收者类型识别接口方法
+func Analyze(files []*goscanner.AstFile, items []parser.ApiItem, receiver *regexp.Regexp) *Report {
+	documented := make(map[string]*parser.ApiItem)
+	for i := range items {
+		if src := items[i].Source; src != nil {
+			documented[sourceKey(src.File, src.Line)] = &items[i]
+		}
+	}
+	registered := registeredFuncs(files)
+
+	report := &Report{Handlers: make([]*Handler, 0), Packages: make([]*Package, 0)}
+	packages := make(map[string]*Package)
+	for _, file := range files {
+		for _, decl := range file.File().Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			line := file.Position(fn.Pos()).Line
+			item := documented[sourceKey(file.Path(), line)]
+			recv := receiverName(fn)
+			isRegistered := registered[fn.Name.Name]
+			if recv != "" {
+				isRegistered = registered[recv+"."+fn.Name.Name] || registered["*."+fn.Name.Name]
+			}
+			isHandler := item != nil || hasTag(fn, parser.TagUrl) || hasTag(fn, parser.TagTitle) ||
+				(fn.Name.IsExported() && (isRegistered || (recv != "" && receiver != nil && receiver.MatchString(recv))))
+			if !isHandler {
+				continue
+			}
+
+			h := &Handler{PkgId: file.PkgId(), File: file.Path(), Line: line, Func: fn.Name.Name}
+			if recv != "" {
+				h.Func = recv + "." + fn.Name.Name
+			}
+			if item != nil {
+				h.Documented = true
+				h.Endpoint = item.Endpoint()
+				h.Issues = itemIssues(item, fn)
+			} else {
+				h.Issues = undocumentedIssues(fn)
+			}
+			report.Handlers = append(report.Handlers, h)
+
+			pkg, ok := packages[h.PkgId]
+			if !ok {
+				pkg = &Package{PkgId: h.PkgId}
+				packages[h.PkgId] = pkg
+				report.Packages = append(report.Packages, pkg)
+			}
+			pkg.Handlers++
+			if h.Documented {
+				pkg.Documented++
+			}
+		}
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].PkgId < report.Packages[j].PkgId
+	})
+	return report
+}
+
+// Percent 所有包的文档覆盖率
+func (r *Report) Percent() float64 {
+	handlers, documented := 0, 0
+	for _, pkg := range r.Packages {
+		handlers += pkg.Handlers
+		documented += pkg.Documented
+	}
+	return percent(documented, handlers)
+}
+
+// Text 纯文本格式，列出没有文档和文档不完整的接口方法，以及每个包的覆盖率
+func (r *Report) Text() string {
+	var sb strings.Builder
+	writeHandlers := func(title string, documented bool) {
+		handlers := make([]*Handler, 0)
+		for _, h := range r.Handlers {
+			if h.Documented == documented && len(h.Issues) > 0 {
+				handlers = append(handlers, h)
+			}
+		}
+		if len(handlers) == 0 {
+			return
+		}
+		sb.WriteString(title + ":\n")
+		for _, h := range handlers {
+			sb.WriteString(fmt.Sprintf("\t%s:%d %s()", h.File, h.Line, h.Func))
+			if h.Endpoint != "" {
+				sb.WriteString(" " + h.Endpoint)
+			}
+			sb.WriteString("\n")
+			for _, issue := range h.Issues {
+				sb.WriteString("\t\t- " + issue + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	writeHandlers("没有接口文档的方法", false)
+	writeHandlers("接口文档不完整的方法", true)
+
+	handlers, documented := 0, 0
+	for _, pkg := range r.Packages {
+		sb.WriteString(fmt.Sprintf("%-40s %d/%d\t%.1f%%\n", pkg.PkgId, pkg.Documented, pkg.Handlers, pkg.Percent()))
+		handlers += pkg.Handlers
+		documented += pkg.Documented
+	}
+	sb.WriteString(fmt.Sprintf("%-40s %d/%d\t%.1f%%", "合计", documented, handlers, r.Percent()))
+	return sb.String()
+}
+
+// itemIssues 接口文档缺少的响应、参数说明和示例值
+func itemIssues(item *parser.ApiItem, fn *ast.FuncDecl) []string {
+	issues := make([]string, 0)
+	if len(item.Responses) == 0 {
+		issues = append(issues, fmt.Sprintf("缺少响应（%s 或 %s）", parser.TagResp, parser.TagSuccess))
+	}
+
+	params := item.Parameters
+	for _, group := range [][]parser.Parameter{params.Path, params.Query, params.Header, params.Cookie, params.FormData} {
+		for _, param := range group {
+			if param.Description == "" {
+				issues = append(issues, fmt.Sprintf("参数 %s 缺少说明", param.Name))
+			}
+		}
+	}
+	if schema := params.JsonSchema; schema != nil {
+		for _, name := range parser.SchemaGetPropertiesOrders(schema) {
+			if prop, ok := schema.Properties[name]; ok && prop.Description == "" {
+				issues = append(issues, fmt.Sprintf("body 参数 %s 缺少说明", name))
+			}
+		}
+	}
+
+	// 只有单个参数可以在注释中填写示例值，结构体参数不检查
+	for _, comment := range fn.Doc.List {
+		value, ok := tagValue(comment.Text, parser.TagParam)
+		if !ok {
+			continue
+		}
+		if m := paramPattern.FindStringSubmatch(value); m != nil && m[5] == "" {
+			issues = append(issues, fmt.Sprintf("参数 %s 缺少示例值", m[2]))
+		}
+	}
+	return issues
+}
+
+// undocumentedIssues 没有接口文档的方法缺少的注释
+func undocumentedIssues(fn *ast.FuncDecl) []string {
+	issues := make([]string, 0)
+	if !hasTag(fn, parser.TagUrl) {
+		issues = append(issues, "缺少 "+parser.TagUrl)
+	}
+	if !hasTag(fn, parser.TagTitle) && !hasTitleLine(fn) {
+		issues = append(issues, "缺少 "+parser.TagTitle)
+	}
+	return issues
+}
+
+// registeredFuncs 在路由中注册的方法：第一个参数为字符串路径的路由方法中，最后一个函数参数。
+//
+//	函数的键为函数名；方法的键为 接收者类型.方法名，无法确定接收者类型时为 *.方法名
+func registeredFuncs(files []*goscanner.AstFile) map[string]bool {
+	funcs := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.File().Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			vars := localVars(fn)
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok || len(call.Args) < 2 || !isStringPath(call.Args[0]) {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || !routeFuncs[strings.ToLower(sel.Sel.Name)] {
+					return true
+				}
+				if key := handlerKey(file, vars, call.Args[len(call.Args)-1]); key != "" {
+					funcs[key] = true
+				}
+				return true
+			})
+		}
+	}
+	return funcs
+}
+
+// isStringPath 是否为字符串字面量，或者字符串字面量拼接的路径，如："/pet"、"/v1" + "/pet"
+func isStringPath(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD && (isStringPath(e.X) || isStringPath(e.Y))
+	case *ast.ParenExpr:
+		return isStringPath(e.X)
+	}
+	return false
+}
+
+// handlerKey 注册的处理方法，如：GetPet、h.GetPet、(*Handler).GetPet、http.HandlerFunc(h.GetPet)，不是函数时返回空字符串
+func handlerKey(file *goscanner.AstFile, vars map[string]string, expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return handlerKey(file, vars, e.X)
+	case *ast.CallExpr:
+		// 类型转换，如：http.HandlerFunc(h.GetPet)
+		if len(e.Args) == 1 {
+			return handlerKey(file, vars, e.Args[0])
+		}
+	case *ast.Ident:
+		// 局部变量不是在包中申明的函数
+		if _, ok := vars[e.Name]; !ok {
+			return e.Name
+		}
+	case *ast.SelectorExpr:
+		name := e.Sel.Name
+		if x, ok := e.X.(*ast.Ident); ok {
+			recv, isVar := vars[x.Name]
+			switch {
+			case isVar && recv != "":
+				return recv + "." + name
+			case !isVar && file.GetImportPkg(x.Name) != "":
+				// 其他包中的函数
+				return name
+			}
+			return "*." + name
+		}
+		// 方法表达式，如：(*Handler).GetPet
+		if recv := typeName(e.X); recv != "" {
+			return recv + "." + name
+		}
+		return "*." + name
+	}
+	return ""
+}
+
+// localVars 方法中的接收者、参数和局部变量 -> 变量的类型名称，无法确定类型时为空字符串
+func localVars(fn *ast.FuncDecl) map[string]string {
+	vars := make(map[string]string)
+	addFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				vars[name.Name] = typeName(field.Type)
+			}
+		}
+	}
+	addFields(fn.Recv)
+	addFields(fn.Type.Params)
+
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					vars[ident.Name] = ""
+					if len(n.Lhs) == len(n.Rhs) {
+						vars[ident.Name] = valueType(n.Rhs[i])
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for i, ident := range n.Names {
+				vars[ident.Name] = typeName(n.Type)
+				if n.Type == nil && len(n.Names) == len(n.Values) {
+					vars[ident.Name] = valueType(n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// valueType 值的类型名称，只支持结构体字面量，如：Handler{}、&Handler{}
+func valueType(expr ast.Expr) string {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		return typeName(lit.Type)
+	}
+	return ""
+}
+
+// receiverName 方法接收者的类型名称，不是方法时返回空字符串
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	return typeName(fn.Recv.List[0].Type)
+}
+
+// typeName 类型表达式的类型名称，如：*Handler、Handler[T]、(*Handler) 为 Handler，其他包中的类型不包括包名
+func typeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			return e.Sel.Name
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// hasTag 方法注释中是否有指定的注释
+func hasTag(fn *ast.FuncDecl, tag string) bool {
+	if fn.Doc == nil {
+		return false
+	}
+	for _, comment := range fn.Doc.List {
+		if value, ok := tagValue(comment.Text, tag); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTitleLine 是否有以方法名开头的标题注释，如：// GetPet 查询宠物详情
+func hasTitleLine(fn *ast.FuncDecl) bool {
+	if fn.Doc == nil {
+		return false
+	}
+	for _, comment := range fn.Doc.List {
+		if value, ok := tagValue(comment.Text, strings.ToLower(fn.Name.Name)); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// tagValue 注释名称为 tag（不区分大小写）时返回注释的值
+func tagValue(text, tag string) (string, bool) {
+	comment := strings.TrimSpace(strings.TrimLeft(text, "//"))
+	fields := strings.Fields(comment)
+	if len(fields) == 0 || strings.ToLower(fields[0]) != tag {
+		return "", false
+	}
+	return strings.TrimSpace(comment[len(fields[0]):]), true
+}
+
+func sourceKey(file string, line int) string {
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
@@ -0,0 +1,118 @@
+package coverage
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+const handlerGo = `package handler
+
+// Pet 宠物
+type Pet struct {
+	Name string ` + "`json:\"name\"`" + ` // 宠物名
+	Age  int    ` + "`json:\"age\"`" + `
+}
+
+type Handler struct{}
+
+// GetPet 查询宠物详情
+//
+// @url 	GET /pet/{petId}
+// @param 	path petId int true "1" "宠物 id"
+// @success Pet{}
+func (h *Handler) GetPet() {}
+
+// AddPet 新建宠物
+//
+// @url 	POST /pet
+// @param 	header token string true "" ""
+// @param 	body Pet{}
+func (h *Handler) AddPet() {}
+
+// DelPet 删除宠物
+func (h *Handler) DelPet() {}
+
+// @url GET /ping
+func Ping() {}
+
+func Health() {}
+
+func notHandler() {}
+
+func Auth() {}
+
+func Version() {}
+
+func (h *Handler) List() {}
+
+type Admin struct{}
+
+func (a *Admin) List() {}
+
+func (a *Admin) Stats() {}
+
+func Register(r interface{ GET(string, ...func()) }, path string) {
+	h := &Handler{}
+	r.GET("/health", Health)
+	r.GET("/pets", Auth, h.List)
+	r.GET("/admin" + "/stats", (*Admin).Stats)
+	r.GET(path, Version)
+}
+`
+
+func TestAnalyze(t *testing.T) {
+	Convey("测试统计文档覆盖率", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module shop\n\ngo 1.19\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "handler.go"), []byte(handlerGo), 0644), ShouldBeNil)
+		p := parser.NewParser()
+		_, err := p.Scan(dir)
+		So(err, ShouldBeNil)
+		items, err := p.Parse()
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 2)
+
+		report := Analyze(p.Scanner().Files(), items, regexp.MustCompile(`Handler$`))
+		So(report.Handlers, ShouldHaveLength, 7)
+		So(report.Packages, ShouldHaveLength, 1)
+		So(report.Packages[0].PkgId, ShouldEqual, "shop")
+		So(report.Packages[0].Handlers, ShouldEqual, 7)
+		So(report.Packages[0].Documented, ShouldEqual, 2)
+
+		issues := make(map[string][]string)
+		for _, h := range report.Handlers {
+			issues[h.Func] = h.Issues
+		}
+		So(issues["Handler.GetPet"], ShouldBeEmpty)
+		So(issues["Handler.AddPet"], ShouldResemble, []string{
+			"缺少响应（@resp 或 @success）",
+			"参数 token 缺少说明",
+			"body 参数 age 缺少说明",
+			"参数 token 缺少示例值",
+		})
+		So(issues["Handler.DelPet"], ShouldResemble, []string{"缺少 @url"})
+		So(issues["Ping"], ShouldResemble, []string{"缺少 @title"})
+		So(issues["Health"], ShouldResemble, []string{"缺少 @url", "缺少 @title"})
+		So(issues["Handler.List"], ShouldResemble, []string{"缺少 @url", "缺少 @title"})
+		So(issues["Admin.Stats"], ShouldResemble, []string{"缺少 @url", "缺少 @title"})
+
+		text := report.Text()
+		So(text, ShouldContainSubstring, "handler.go:23 Handler.AddPet() POST /pet\n")
+		So(text, ShouldContainSubstring, "28.6%")
+
+		Convey("不指定接收者类型时，只统计有注释或注册路由的方法", func() {
+			report := Analyze(p.Scanner().Files(), items, nil)
+			funcs := make([]string, 0)
+			for _, h := range report.Handlers {
+				funcs = append(funcs, h.Func)
+			}
+			// 中间件、其他类型的同名方法、路径不是字符串的路由不统计
+			So(funcs, ShouldResemble, []string{"Handler.GetPet", "Handler.AddPet", "Ping", "Health", "Handler.List", "Admin.Stats"})
+			So(report.Percent(), ShouldAlmostEqual, 100.0/3)
+		})
+	})
+}
@@ -0,0 +1,370 @@
+// Package diff 比较两份 OpenAPI 2.0（Swagger）文档，找出接口的变更并区分是否为破坏性变更。
+package diff
+
+import (
+	"fmt"
+	"github.com/go-openapi/spec"
+	"sort"
+	"strings"
+)
+
+// Level 变更级别
+type Level string
+
+const (
+	Breaking    Level = "breaking"     // 破坏性变更，已有的调用方可能无法正常工作
+	NonBreaking Level = "non-breaking" // 非破坏性变更
+)
+
+// 变更类型
+const (
+	KindEndpointAdded        = "endpoint-added"         // 新增接口
+	KindEndpointRemoved      = "endpoint-removed"       // 删除接口
+	KindParamAdded           = "param-added"            // 新增参数
+	KindParamRemoved         = "param-removed"          // 删除参数
+	KindParamRequired        = "param-required"         // 参数变为必填
+	KindParamOptional        = "param-optional"         // 参数变为选填
+	KindParamType            = "param-type"             // 参数类型变更
+	KindParamEnum            = "param-enum"             // 参数枚举值变更
+	KindRequestFieldAdded    = "request-field-added"    // 请求体新增字段
+	KindRequestFieldRemoved  = "request-field-removed"  // 请求体删除字段
+	KindRequestFieldRequired = "request-field-required" // 请求体字段变为必填
+	KindRequestFieldType     = "request-field-type"     // 请求体字段类型变更
+	KindResponseAdded        = "response-added"         // 新增响应状态码
+	KindResponseRemoved      = "response-removed"       // 删除响应状态码
+	KindResponseFieldAdded   = "response-field-added"   // 响应新增字段
+	KindResponseFieldRemoved = "response-field-removed" // 响应删除字段
+	KindResponseFieldType    = "response-field-type"    // 响应字段类型变更
+)
+
+// Change 单个变更
+type Change struct {
+	Level   Level  `json:"level"`   // 变更级别
+	Kind    string `json:"kind"`    // 变更类型
+	Method  string `json:"method"`  // http 请求方式
+	Path    string `json:"path"`    // http 请求路径
+	Target  string `json:"target"`  // 变更的参数名或字段路径，接口级别的变更为空
+	Message string `json:"message"` // 变更说明
+}
+
+// Endpoint 接口标识，如：GET /pet/{petId}
+func (c *Change) Endpoint() string {
+	return strings.ToUpper(c.Method) + " " + c.Path
+}
+
+// Report 比较结果
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// HasBreaking 是否存在破坏性变更
+func (r *Report) HasBreaking() bool {
+	return r.Count(Breaking) > 0
+}
+
+// Count 统计指定级别的变更数
+func (r *Report) Count(level Level) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+// methods 按固定顺序列出路径下的所有接口
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+func operation(item spec.PathItem, method string) *spec.Operation {
+	switch method {
+	case "get":
+		return item.Get
+	case "put":
+		return item.Put
+	case "post":
+		return item.Post
+	case "delete":
+		return item.Delete
+	case "options":
+		return item.Options
+	case "head":
+		return item.Head
+	case "patch":
+		return item.Patch
+	}
+	return nil
+}
+
+func sortedPaths(doc *spec.Swagger) []string {
+	paths := make([]string, 0)
+	if doc.Paths != nil {
+		for p := range doc.Paths.Paths {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func pathItem(doc *spec.Swagger, path string) (spec.PathItem, bool) {
+	if doc.Paths == nil {
+		return spec.PathItem{}, false
+	}
+	item, ok := doc.Paths.Paths[path]
+	return item, ok
+}
+
+// Compare 逐个接口比较新旧两份文档
+func Compare(oldDoc, newDoc *spec.Swagger) *Report {
+	c := &comparer{oldDoc: oldDoc, newDoc: newDoc, report: &Report{Changes: make([]Change, 0)}}
+
+	// 合并两份文档中的路径，保证输出顺序稳定
+	paths := append(sortedPaths(oldDoc), sortedPaths(newDoc)...)
+	sort.Strings(paths)
+	for i, path := range paths {
+		if i > 0 && paths[i-1] == path {
+			continue
+		}
+		oldItem, _ := pathItem(oldDoc, path)
+		newItem, _ := pathItem(newDoc, path)
+		for _, method := range methods {
+			c.compareOperation(method, path, operation(oldItem, method), operation(newItem, method))
+		}
+	}
+	return c.report
+}
+
+type comparer struct {
+	oldDoc, newDoc *spec.Swagger
+	report         *Report
+}
+
+func (c *comparer) add(level Level, kind, method, path, target, format string, a ...interface{}) {
+	c.report.Changes = append(c.report.Changes, Change{
+		Level:   level,
+		Kind:    kind,
+		Method:  method,
+		Path:    path,
+		Target:  target,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *comparer) compareOperation(method, path string, oldOp, newOp *spec.Operation) {
+	switch {
+	case oldOp == nil && newOp == nil:
+		return
+	case oldOp == nil:
+		c.add(NonBreaking, KindEndpointAdded, method, path, "", "新增接口 %s", newOp.Summary)
+		return
+	case newOp == nil:
+		c.add(Breaking, KindEndpointRemoved, method, path, "", "删除接口 %s", oldOp.Summary)
+		return
+	}
+	c.compareParameters(method, path, oldOp.Parameters, newOp.Parameters)
+	c.compareResponses(method, path, oldOp.Responses, newOp.Responses)
+}
+
+func paramKey(param spec.Parameter) string {
+	return param.In + ":" + param.Name
+}
+
+func (c *comparer) compareParameters(method, path string, oldParams, newParams []spec.Parameter) {
+	var oldBody, newBody *spec.Schema
+	oldMap := make(map[string]spec.Parameter)
+	for _, param := range oldParams {
+		if param.In == "body" {
+			oldBody = param.Schema
+			continue
+		}
+		oldMap[paramKey(param)] = param
+	}
+
+	newKeys := make(map[string]bool)
+	for _, param := range newParams {
+		if param.In == "body" {
+			newBody = param.Schema
+			continue
+		}
+		key := paramKey(param)
+		newKeys[key] = true
+		oldParam, ok := oldMap[key]
+		if !ok {
+			if param.Required {
+				c.add(Breaking, KindParamAdded, method, path, key, "新增必填参数 %s", key)
+			} else {
+				c.add(NonBreaking, KindParamAdded, method, path, key, "新增选填参数 %s", key)
+			}
+			continue
+		}
+		if !oldParam.Required && param.Required {
+			c.add(Breaking, KindParamRequired, method, path, key, "参数 %s 变为必填", key)
+		} else if oldParam.Required && !param.Required {
+			c.add(NonBreaking, KindParamOptional, method, path, key, "参数 %s 变为选填", key)
+		}
+		if oldType, newType := paramType(oldParam), paramType(param); oldType != newType {
+			c.add(Breaking, KindParamType, method, path, key, "参数 %s 类型由 %s 变为 %s", key, oldType, newType)
+		}
+		c.compareParamEnum(method, path, key, paramEnum(oldParam), paramEnum(param))
+	}
+	for _, param := range oldParams {
+		if param.In == "body" {
+			continue
+		}
+		if key := paramKey(param); !newKeys[key] {
+			c.add(NonBreaking, KindParamRemoved, method, path, key, "删除参数 %s", key)
+		}
+	}
+
+	c.compareRequestBody(method, path, oldBody, newBody)
+}
+
+// paramType 参数类型，包括格式，数组包含元素类型，如：array[integer(int64)]
+func paramType(param spec.Parameter) string {
+	typ := typeWithFormat(param.Type, param.Format)
+	if param.Type == "array" && param.Items != nil {
+		typ += "[" + typeWithFormat(param.Items.Type, param.Items.Format) + "]"
+	}
+	return typ
+}
+
+// paramEnum 参数的枚举值，数组参数使用元素的枚举值
+func paramEnum(param spec.Parameter) []string {
+	enum := param.Enum
+	if param.Type == "array" && param.Items != nil {
+		enum = param.Items.Enum
+	}
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		values = append(values, fmt.Sprint(v))
+	}
+	return values
+}
+
+// compareParamEnum 比较参数的枚举值：限制更严格（新增限制、删除枚举值）为破坏性变更
+func (c *comparer) compareParamEnum(method, path, key string, oldEnum, newEnum []string) {
+	switch {
+	case len(oldEnum) == 0 && len(newEnum) == 0:
+	case len(oldEnum) == 0:
+		c.add(Breaking, KindParamEnum, method, path, key, "参数 %s 限制枚举值为 %s", key, strings.Join(newEnum, ", "))
+	case len(newEnum) == 0:
+		c.add(NonBreaking, KindParamEnum, method, path, key, "参数 %s 取消枚举值限制", key)
+	default:
+		if removed := subtract(oldEnum, newEnum); len(removed) > 0 {
+			c.add(Breaking, KindParamEnum, method, path, key, "参数 %s 删除枚举值 %s", key, strings.Join(removed, ", "))
+		}
+		if added := subtract(newEnum, oldEnum); len(added) > 0 {
+			c.add(NonBreaking, KindParamEnum, method, path, key, "参数 %s 新增枚举值 %s", key, strings.Join(added, ", "))
+		}
+	}
+}
+
+// subtract a 中不在 b 中的值
+func subtract(a, b []string) []string {
+	exists := make(map[string]bool, len(b))
+	for _, v := range b {
+		exists[v] = true
+	}
+	values := make([]string, 0)
+	for _, v := range a {
+		if !exists[v] {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (c *comparer) compareRequestBody(method, path string, oldBody, newBody *spec.Schema) {
+	oldFields, newFields := flatten(c.oldDoc, oldBody), flatten(c.newDoc, newBody)
+	for _, name := range sortedKeys(newFields) {
+		newField := newFields[name]
+		oldField, ok := oldFields[name]
+		if name == rootField {
+			// 新增或删除整个请求体时只报告其中的字段
+			if ok && oldField.Type != newField.Type {
+				c.add(Breaking, KindRequestFieldType, method, path, name, "请求体类型由 %s 变为 %s", oldField.Type, newField.Type)
+			}
+			continue
+		}
+		if !ok {
+			if newField.Required {
+				c.add(Breaking, KindRequestFieldAdded, method, path, name, "请求体新增必填字段 %s", name)
+			} else {
+				c.add(NonBreaking, KindRequestFieldAdded, method, path, name, "请求体新增字段 %s", name)
+			}
+			continue
+		}
+		if !oldField.Required && newField.Required {
+			c.add(Breaking, KindRequestFieldRequired, method, path, name, "请求体字段 %s 变为必填", name)
+		}
+		if oldField.Type != newField.Type {
+			c.add(Breaking, KindRequestFieldType, method, path, name, "请求体字段 %s 类型由 %s 变为 %s", name, oldField.Type, newField.Type)
+		}
+	}
+	for _, name := range sortedKeys(oldFields) {
+		if _, ok := newFields[name]; !ok && name != rootField {
+			c.add(NonBreaking, KindRequestFieldRemoved, method, path, name, "请求体删除字段 %s", name)
+		}
+	}
+}
+
+func statusCodes(resps *spec.Responses) map[int]spec.Response {
+	if resps == nil || resps.StatusCodeResponses == nil {
+		return map[int]spec.Response{}
+	}
+	return resps.StatusCodeResponses
+}
+
+func (c *comparer) compareResponses(method, path string, oldResps, newResps *spec.Responses) {
+	oldCodes, newCodes := statusCodes(oldResps), statusCodes(newResps)
+
+	codes := make([]int, 0)
+	for code := range oldCodes {
+		codes = append(codes, code)
+	}
+	for code := range newCodes {
+		if _, ok := oldCodes[code]; !ok {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		target := fmt.Sprint(code)
+		oldResp, oldOk := oldCodes[code]
+		newResp, newOk := newCodes[code]
+		if !oldOk {
+			c.add(NonBreaking, KindResponseAdded, method, path, target, "新增响应 %d", code)
+			continue
+		}
+		if !newOk {
+			c.add(Breaking, KindResponseRemoved, method, path, target, "删除响应 %d", code)
+			continue
+		}
+
+		oldFields, newFields := flatten(c.oldDoc, oldResp.Schema), flatten(c.newDoc, newResp.Schema)
+		for _, name := range sortedKeys(oldFields) {
+			newField, ok := newFields[name]
+			if name == rootField {
+				// 新增或删除整个响应数据时只报告其中的字段
+				if ok && oldFields[name].Type != newField.Type {
+					c.add(Breaking, KindResponseFieldType, method, path, target, "响应 %d 类型由 %s 变为 %s", code, oldFields[name].Type, newField.Type)
+				}
+				continue
+			}
+			if !ok {
+				c.add(Breaking, KindResponseFieldRemoved, method, path, target+name, "响应 %d 删除字段 %s", code, name)
+				continue
+			}
+			if oldType := oldFields[name].Type; oldType != newField.Type {
+				c.add(Breaking, KindResponseFieldType, method, path, target+name, "响应 %d 字段 %s 类型由 %s 变为 %s", code, name, oldType, newField.Type)
+			}
+		}
+		for _, name := range sortedKeys(newFields) {
+			if _, ok := oldFields[name]; !ok && name != rootField {
+				c.add(NonBreaking, KindResponseFieldAdded, method, path, target+name, "响应 %d 新增字段 %s", code, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func newDoc(paths map[string]spec.PathItem) *spec.Swagger {
+	return &spec.Swagger{SwaggerProps: spec.SwaggerProps{Paths: &spec.Paths{Paths: paths}}}
+}
+
+func objectSchema(required []string, props map[string]spec.Schema) *spec.Schema {
+	return &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"object"}, Required: required, Properties: props}}
+}
+
+func TestCompare(t *testing.T) {
+	Convey("测试比较两份文档", t, func() {
+		petSchema := objectSchema([]string{"id"}, map[string]spec.Schema{
+			"id":   *spec.Int64Property(),
+			"name": *spec.StringProperty(),
+		})
+		oldOp := spec.NewOperation("").
+			AddParam(spec.QueryParam("status").Typed("string", "")).
+			RespondsWith(200, spec.NewResponse().WithSchema(petSchema))
+		oldDoc := newDoc(map[string]spec.PathItem{
+			"/pet/{petId}": {PathItemProps: spec.PathItemProps{Get: oldOp, Delete: spec.NewOperation("")}},
+		})
+
+		newSchema := objectSchema([]string{"id"}, map[string]spec.Schema{
+			"id": *spec.StringProperty(),
+		})
+		newOp := spec.NewOperation("").
+			AddParam(spec.QueryParam("status").Typed("string", "").AsRequired()).
+			AddParam(spec.HeaderParam("X-Trace").Typed("string", "").AsOptional()).
+			RespondsWith(200, spec.NewResponse().WithSchema(newSchema))
+		newDoc := newDoc(map[string]spec.PathItem{
+			"/pet/{petId}": {PathItemProps: spec.PathItemProps{Get: newOp}},
+			"/pet":         {PathItemProps: spec.PathItemProps{Post: spec.NewOperation("")}},
+		})
+
+		report := Compare(oldDoc, newDoc)
+		kinds := make(map[string]Level)
+		for _, c := range report.Changes {
+			kinds[c.Kind+" "+c.Endpoint()+" "+c.Target] = c.Level
+		}
+		So(kinds, ShouldResemble, map[string]Level{
+			"endpoint-added POST /pet ":                        NonBreaking,
+			"endpoint-removed DELETE /pet/{petId} ":            Breaking,
+			"param-required GET /pet/{petId} query:status":     Breaking,
+			"param-added GET /pet/{petId} header:X-Trace":      NonBreaking,
+			"response-field-removed GET /pet/{petId} 200/name": Breaking,
+			"response-field-type GET /pet/{petId} 200/id":      Breaking,
+		})
+		So(report.HasBreaking(), ShouldBeTrue)
+		So(Compare(oldDoc, oldDoc).Changes, ShouldBeEmpty)
+
+	})
+}
+
+func TestCompareTypeFormat(t *testing.T) {
+	Convey("测试比较类型时包括格式，整个请求体或响应的类型变化也会报告", t, func() {
+		petSchema := objectSchema(nil, map[string]spec.Schema{
+			"birthday": *spec.StringProperty(),
+		})
+		oldDoc := newDoc(map[string]spec.PathItem{
+			"/pet": {PathItemProps: spec.PathItemProps{
+				Post: spec.NewOperation("").
+					AddParam(spec.QueryParam("limit").Typed("integer", "int32")).
+					AddParam(spec.BodyParam("body", petSchema)).
+					RespondsWith(200, spec.NewResponse().WithSchema(petSchema)),
+				Put: spec.NewOperation("").AddParam(spec.BodyParam("body", petSchema)),
+			}},
+		})
+		newPet := objectSchema(nil, map[string]spec.Schema{
+			"birthday": *spec.DateTimeProperty(),
+		})
+		newDoc := newDoc(map[string]spec.PathItem{
+			"/pet": {PathItemProps: spec.PathItemProps{
+				Post: spec.NewOperation("").
+					AddParam(spec.QueryParam("limit").Typed("integer", "int64")).
+					AddParam(spec.BodyParam("body", newPet)).
+					RespondsWith(200, spec.NewResponse().WithSchema(spec.ArrayProperty(petSchema))),
+				Put: spec.NewOperation("").AddParam(spec.BodyParam("body", spec.ArrayProperty(petSchema))),
+			}},
+		})
+
+		messages := make(map[string]string)
+		for _, c := range Compare(oldDoc, newDoc).Changes {
+			if c.Kind == KindParamType || c.Kind == KindRequestFieldType || c.Kind == KindResponseFieldType {
+				messages[c.Kind+" "+c.Endpoint()+" "+c.Target] = c.Message
+			}
+		}
+		So(messages, ShouldResemble, map[string]string{
+			"param-type POST /pet query:limit":       "参数 query:limit 类型由 integer(int32) 变为 integer(int64)",
+			"request-field-type POST /pet /birthday": "请求体字段 /birthday 类型由 string 变为 string(date-time)",
+			"response-field-type POST /pet 200":      "响应 200 类型由 object 变为 array",
+			"request-field-type PUT /pet ":           "请求体类型由 object 变为 array",
+		})
+	})
+}
+
+func TestCompareParamEnum(t *testing.T) {
+	Convey("测试比较参数的枚举值和数组元素类型", t, func() {
+		oldDoc := newDoc(map[string]spec.PathItem{
+			"/pet": {PathItemProps: spec.PathItemProps{
+				Get: spec.NewOperation("").
+					AddParam(spec.QueryParam("status").Typed("string", "").WithEnum("available", "sold")).
+					AddParam(spec.QueryParam("kind").Typed("string", "").WithEnum("cat")).
+					AddParam(spec.QueryParam("sort").Typed("string", "")).
+					AddParam(spec.QueryParam("tags").Typed("array", "").CollectionOf(spec.NewItems().Typed("string", "").WithEnum("a", "b"), "csv")).
+					AddParam(spec.QueryParam("ids").Typed("array", "").CollectionOf(spec.NewItems().Typed("integer", "int32"), "csv")),
+			}},
+		})
+		newDoc := newDoc(map[string]spec.PathItem{
+			"/pet": {PathItemProps: spec.PathItemProps{
+				Get: spec.NewOperation("").
+					AddParam(spec.QueryParam("status").Typed("string", "").WithEnum("available", "pending")).
+					AddParam(spec.QueryParam("kind").Typed("string", "")).
+					AddParam(spec.QueryParam("sort").Typed("string", "").WithEnum("asc", "desc")).
+					AddParam(spec.QueryParam("tags").Typed("array", "").CollectionOf(spec.NewItems().Typed("string", "").WithEnum("a", "b", "c"), "csv")).
+					AddParam(spec.QueryParam("ids").Typed("array", "").CollectionOf(spec.NewItems().Typed("string", ""), "csv")),
+			}},
+		})
+
+		changes := make(map[string]Level)
+		for _, c := range Compare(oldDoc, newDoc).Changes {
+			changes[c.Kind+" "+c.Message] = c.Level
+		}
+		So(changes, ShouldResemble, map[string]Level{
+			"param-enum 参数 query:status 删除枚举值 sold":                              Breaking,
+			"param-enum 参数 query:status 新增枚举值 pending":                           NonBreaking,
+			"param-enum 参数 query:kind 取消枚举值限制":                                   NonBreaking,
+			"param-enum 参数 query:sort 限制枚举值为 asc, desc":                          Breaking,
+			"param-enum 参数 query:tags 新增枚举值 c":                                   NonBreaking,
+			"param-type 参数 query:ids 类型由 array[integer(int32)] 变为 array[string]": Breaking,
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"os"
+	"strings"
+)
+
+// 报告格式
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// LoadFile 读取 OpenAPI 2.0（Swagger）格式的 json 文件
+func LoadFile(file string) (*spec.Swagger, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	doc := &spec.Swagger{}
+	if err = json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("解析文档 %s 出错: %s", file, err)
+	}
+	return doc, nil
+}
+
+// Format 按指定格式输出报告
+func (r *Report) Format(format string) (string, error) {
+	switch format {
+	case "", FormatText:
+		return r.Text(), nil
+	case FormatMarkdown, "md":
+		return r.Markdown(), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(r, "", "    ")
+		return string(data), err
+	}
+	return "", fmt.Errorf("不支持 %s 报告格式", format)
+}
+
+func levelName(level Level) string {
+	if level == Breaking {
+		return "破坏性"
+	}
+	return "非破坏性"
+}
+
+func (r *Report) summary() string {
+	return fmt.Sprintf("破坏性变更 %d 项，非破坏性变更 %d 项", r.Count(Breaking), r.Count(NonBreaking))
+}
+
+// Text 纯文本格式
+func (r *Report) Text() string {
+	var sb strings.Builder
+	for _, c := range r.Changes {
+		sb.WriteString(fmt.Sprintf("[%s] %s %s\n", levelName(c.Level), c.Endpoint(), c.Message))
+	}
+	sb.WriteString(r.summary())
+	return sb.String()
+}
+
+// Markdown Markdown 表格格式
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("## API 变更\n\n")
+	sb.WriteString(r.summary() + "\n")
+	if len(r.Changes) == 0 {
+		return sb.String()
+	}
+	sb.WriteString("\n| 级别 | 接口 | 变更 |\n")
+	sb.WriteString("|----|----|----|\n")
+	for _, c := range r.Changes {
+		level := levelName(c.Level)
+		if c.Level == Breaking {
+			level = "**" + level + "**"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", level, c.Endpoint(), strings.ReplaceAll(c.Message, "|", `\|`)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"github.com/go-openapi/spec"
+	"sort"
+	"strings"
+)
+
+// maxDepth 展开 schema 的最大层数，避免递归引用导致死循环
+const maxDepth = 16
+
+// rootField 根节点的字段路径
+const rootField = ""
+
+// field 展开后的字段
+type field struct {
+	Type     string
+	Required bool
+}
+
+// flatten 将 schema 展开为 字段路径（JSON 指针，数组元素用 [] 表示）-> 字段 的映射。
+//
+// 根节点的路径为空字符串，如：/data/pets/[]/name
+func flatten(doc *spec.Swagger, schema *spec.Schema) map[string]field {
+	fields := make(map[string]field)
+	if schema != nil {
+		flattenInto(doc, schema, rootField, false, fields, 0)
+	}
+	return fields
+}
+
+func flattenInto(doc *spec.Swagger, schema *spec.Schema, prefix string, required bool, fields map[string]field, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	schema = resolveRef(doc, schema)
+
+	// 组合类型，合并每个子类型的字段
+	if len(schema.AllOf) > 0 {
+		for i := range schema.AllOf {
+			flattenInto(doc, &schema.AllOf[i], prefix, required, fields, depth+1)
+		}
+		return
+	}
+
+	// 根节点也记录类型，用于比较整个请求体或响应在数组、对象等类型之间的变化
+	if f, ok := fields[prefix]; !ok || f.Type == "" {
+		fields[prefix] = field{Type: schemaType(schema), Required: required}
+	}
+
+	for name, prop := range schema.Properties {
+		prop := prop
+		flattenInto(doc, &prop, prefix+"/"+escapePointer(name), strSliceContains(schema.Required, name), fields, depth+1)
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		flattenInto(doc, schema.Items.Schema, prefix+"/[]", false, fields, depth+1)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		flattenInto(doc, schema.AdditionalProperties.Schema, prefix+"/{}", false, fields, depth+1)
+	}
+}
+
+// resolveRef 解析引用 #/definitions/xxx 的 schema
+func resolveRef(doc *spec.Swagger, schema *spec.Schema) *spec.Schema {
+	for i := 0; i < maxDepth && doc != nil; i++ {
+		ref := schema.Ref.String()
+		if ref == "" {
+			break
+		}
+		def, ok := doc.Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+		if !ok {
+			break
+		}
+		schema = &def
+	}
+	return schema
+}
+
+// schemaType 类型和格式，如：integer(int64)、string(date-time)
+func schemaType(schema *spec.Schema) string {
+	if len(schema.Type) == 0 {
+		if len(schema.AllOf) > 0 || len(schema.Properties) > 0 {
+			return "object"
+		}
+		return ""
+	}
+	return typeWithFormat(schema.Type[0], schema.Format)
+}
+
+func typeWithFormat(typ, format string) string {
+	if format != "" {
+		typ += "(" + format + ")"
+	}
+	return typ
+}
+
+// escapePointer 按 JSON 指针规则转义属性名
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func sortedKeys(fields map[string]field) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func strSliceContains(opts []string, val string) bool {
+	for _, opt := range opts {
+		if opt == val {
+			return true
+		}
+	}
+	return false
+}
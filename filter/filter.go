@@ -0,0 +1,264 @@
+// Package filter 按目录、Go 包、标签、请求方式和路径筛选接口文档。
+//
+// 使用示例：
+//
+//	f, err := filter.New(filter.Options{Folders: []string{"宠物商城/**"}, ExcludeTags: []string{"internal"}})
+//	...
+//	items = f.Apply(items)
+package filter
+
+import (
+	"fmt"
+	"github.com/whaios/apigo/parser"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Options 筛选条件，同一类条件满足任意一个即可，不同类的条件需要同时满足；
+// 满足任意一个排除条件的接口会被排除。
+type Options struct {
+	Folders        []string `json:"folders,omitempty"`        // 包含的目录，支持通配符：* 匹配一级目录中的任意字符，** 匹配任意多级目录，如：宠物商城/**，\/ 表示字符 /
+	ExcludeFolders []string `json:"excludeFolders,omitempty"` // 排除的目录
+
+	Packages        []string `json:"packages,omitempty"`        // 包含的 Go 包，... 匹配任意字符，如：petshop/pet/...
+	ExcludePackages []string `json:"excludePackages,omitempty"` // 排除的 Go 包
+
+	Tags        []string `json:"tags,omitempty"`        // 包含的标签（@tag）
+	ExcludeTags []string `json:"excludeTags,omitempty"` // 排除的标签
+
+	Statuses        []string `json:"statuses,omitempty"`        // 包含的接口状态，支持别名，如：released 或 已发布
+	ExcludeStatuses []string `json:"excludeStatuses,omitempty"` // 排除的接口状态，如：排除设计中（designing）的接口，不对外公开
+
+	Methods []string `json:"methods,omitempty"` // 包含的请求方式，如：GET
+
+	Paths        []string `json:"paths,omitempty"`        // 包含的请求路径，正则表达式，只需匹配路径中的一部分，需要完全匹配时使用 ^ 和 $
+	ExcludePaths []string `json:"excludePaths,omitempty"` // 排除的请求路径，正则表达式
+}
+
+// New 创建筛选器
+func New(opts Options) (*Filter, error) {
+	f := &Filter{
+		tags:        opts.Tags,
+		excludeTags: opts.ExcludeTags,
+	}
+	for _, method := range opts.Methods {
+		f.methods = append(f.methods, strings.ToUpper(method))
+	}
+
+	var err error
+	if f.statuses, err = parseStatuses(opts.Statuses); err != nil {
+		return nil, err
+	}
+	if f.excludeStatuses, err = parseStatuses(opts.ExcludeStatuses); err != nil {
+		return nil, err
+	}
+	if f.folders, err = compileFolders(opts.Folders); err != nil {
+		return nil, err
+	}
+	if f.excludeFolders, err = compileFolders(opts.ExcludeFolders); err != nil {
+		return nil, err
+	}
+	if f.packages, err = compile(opts.Packages, pkgRegexp); err != nil {
+		return nil, err
+	}
+	if f.excludePackages, err = compile(opts.ExcludePackages, pkgRegexp); err != nil {
+		return nil, err
+	}
+	if f.paths, err = compile(opts.Paths, func(s string) string { return s }); err != nil {
+		return nil, err
+	}
+	if f.excludePaths, err = compile(opts.ExcludePaths, func(s string) string { return s }); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Filter 筛选器
+type Filter struct {
+	folders, excludeFolders   []parser.Folder
+	packages, excludePackages []*regexp.Regexp
+	tags, excludeTags         []string
+	statuses, excludeStatuses []parser.Status
+	methods                   []string
+	paths, excludePaths       []*regexp.Regexp
+}
+
+// Empty 没有任何筛选条件
+func (f *Filter) Empty() bool {
+	return f == nil || len(f.folders)+len(f.excludeFolders)+len(f.packages)+len(f.excludePackages)+
+		len(f.tags)+len(f.excludeTags)+len(f.statuses)+len(f.excludeStatuses)+len(f.methods)+len(f.paths)+len(f.excludePaths) == 0
+}
+
+// Match 接口是否满足筛选条件
+func (f *Filter) Match(item *parser.ApiItem) bool {
+	if f.Empty() {
+		return true
+	}
+
+	// 包含条件
+	if len(f.folders) > 0 && !matchAnyFolder(f.folders, item.Folder) {
+		return false
+	}
+	if len(f.packages) > 0 && !matchAny(f.packages, item.PkgId) {
+		return false
+	}
+	if len(f.tags) > 0 && !containsAny(f.tags, item.Tags) {
+		return false
+	}
+	if len(f.statuses) > 0 && !containsStatus(f.statuses, item.Status) {
+		return false
+	}
+	if len(f.methods) > 0 && !containsAny(f.methods, []string{strings.ToUpper(item.Method)}) {
+		return false
+	}
+	if len(f.paths) > 0 && !matchAny(f.paths, item.Path) {
+		return false
+	}
+
+	// 排除条件
+	return !matchAnyFolder(f.excludeFolders, item.Folder) &&
+		!matchAny(f.excludePackages, item.PkgId) &&
+		!containsAny(f.excludeTags, item.Tags) &&
+		!containsStatus(f.excludeStatuses, item.Status) &&
+		!matchAny(f.excludePaths, item.Path)
+}
+
+// HasPackages 是否有 Go 包的筛选条件
+func (f *Filter) HasPackages() bool {
+	return f != nil && len(f.packages)+len(f.excludePackages) > 0
+}
+
+// MatchRemote 从 Apifox 项目中导出的接口是否满足筛选条件。
+//
+//	导出的接口没有 Go 包信息，有 Go 包的筛选条件时无法判断，都视为不满足。
+func (f *Filter) MatchRemote(item *parser.ApiItem) bool {
+	if f.HasPackages() {
+		return false
+	}
+	return f.Match(item)
+}
+
+// Apply 筛选出满足条件的接口
+func (f *Filter) Apply(items []parser.ApiItem) []parser.ApiItem {
+	if f.Empty() {
+		return items
+	}
+	result := make([]parser.ApiItem, 0, len(items))
+	for i := range items {
+		if f.Match(&items[i]) {
+			result = append(result, items[i])
+		}
+	}
+	return result
+}
+
+func compile(patterns []string, toRegexp func(string) string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(toRegexp(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("筛选条件 %q 格式错误: %s", pattern, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// compileFolders 按 / 将目录通配符拆分为每级目录的通配符，并检查格式
+func compileFolders(patterns []string) ([]parser.Folder, error) {
+	folders := make([]parser.Folder, 0, len(patterns))
+	for _, pattern := range patterns {
+		folder, err := parser.ParseFolder(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("筛选条件 %q 格式错误: %s", pattern, err)
+		}
+		for _, name := range folder {
+			if _, err = path.Match(name, ""); err != nil {
+				return nil, fmt.Errorf("筛选条件 %q 格式错误: %s", pattern, err)
+			}
+		}
+		folders = append(folders, folder)
+	}
+	return folders, nil
+}
+
+func matchAnyFolder(patterns []parser.Folder, folder parser.Folder) bool {
+	for _, pattern := range patterns {
+		if matchFolder(pattern, folder) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFolder 逐级匹配目录：** 匹配任意多级目录（包括零级），其他通配符只匹配一级目录
+func matchFolder(pattern, folder parser.Folder) bool {
+	if len(pattern) == 0 {
+		return len(folder) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(folder); i++ {
+			if matchFolder(pattern[1:], folder[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(folder) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], folder[0]); !ok {
+		return false
+	}
+	return matchFolder(pattern[1:], folder[1:])
+}
+
+// pkgRegexp 将 Go 包模式转换为正则表达式：... 匹配任意字符，x/... 同时匹配 x 本身
+func pkgRegexp(pattern string) string {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `/\.\.\.`, `(?:/.*)?`)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	return "^" + re + "$"
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(opts []string, values []string) bool {
+	for _, opt := range opts {
+		for _, v := range values {
+			if opt == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseStatuses(values []string) ([]parser.Status, error) {
+	statuses := make([]parser.Status, 0, len(values))
+	for _, v := range values {
+		status, err := parser.ParseStatus(v)
+		if err != nil {
+			return nil, fmt.Errorf("筛选条件 %q 格式错误: %s", v, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func containsStatus(statuses []parser.Status, status parser.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"testing"
+)
+
+var testItems = []parser.ApiItem{
+	{Title: "查询宠物", Folder: parser.Folder{"宠物商城", "宠物管理"}, Method: "get", Path: "/pet/{petId}", PkgId: "petshop/pet", Tags: []string{"pet"}, Status: parser.StatusReleased},
+	{Title: "新建宠物", Folder: parser.Folder{"宠物商城", "宠物管理"}, Method: "post", Path: "/pet", PkgId: "petshop/pet", Tags: []string{"pet", "internal"}, Status: parser.StatusDesigning},
+	{Title: "查询订单", Folder: parser.Folder{"宠物商城", "商店", "订单"}, Method: "get", Path: "/store/order", PkgId: "petshop/store/order"},
+	{Title: "登录", Folder: parser.Folder{"用户", "v1/v2"}, Method: "post", Path: "/user/login", PkgId: "petshop/user"},
+}
+
+func titles(f *Filter) []string {
+	result := make([]string, 0)
+	for _, item := range f.Apply(testItems) {
+		result = append(result, item.Title)
+	}
+	return result
+}
+
+func TestFilter(t *testing.T) {
+	Convey("测试没有筛选条件", t, func() {
+		f, err := New(Options{})
+		So(err, ShouldBeNil)
+		So(f.Empty(), ShouldBeTrue)
+		So(f.Apply(testItems), ShouldHaveLength, len(testItems))
+	})
+
+	Convey("测试按目录筛选", t, func() {
+		f, err := New(Options{Folders: []string{"宠物商城/**"}})
+		So(err, ShouldBeNil)
+		So(titles(f), ShouldResemble, []string{"查询宠物", "新建宠物", "查询订单"})
+
+		f, _ = New(Options{Folders: []string{"宠物商城/*"}})
+		So(titles(f), ShouldResemble, []string{"查询宠物", "新建宠物"})
+
+		f, _ = New(Options{Folders: []string{"**/订单"}})
+		So(titles(f), ShouldResemble, []string{"查询订单"})
+
+		f, _ = New(Options{Folders: []string{"宠物商城/**"}, ExcludeFolders: []string{"宠物商城/商店/**"}})
+		So(titles(f), ShouldResemble, []string{"查询宠物", "新建宠物"})
+
+		f, _ = New(Options{Folders: []string{`用户/v1\/*`}})
+		So(titles(f), ShouldResemble, []string{"登录"})
+
+		f, _ = New(Options{Folders: []string{"用户/v1"}})
+		So(titles(f), ShouldBeEmpty)
+	})
+
+	Convey("测试按 Go 包筛选", t, func() {
+		f, _ := New(Options{Packages: []string{"petshop/store/..."}})
+		So(titles(f), ShouldResemble, []string{"查询订单"})
+
+		f, _ = New(Options{Packages: []string{"petshop/..."}, ExcludePackages: []string{"petshop/pet"}})
+		So(titles(f), ShouldResemble, []string{"查询订单", "登录"})
+	})
+
+	Convey("测试按标签筛选", t, func() {
+		f, _ := New(Options{Tags: []string{"pet"}, ExcludeTags: []string{"internal"}})
+		So(titles(f), ShouldResemble, []string{"查询宠物"})
+	})
+
+	Convey("测试按接口状态筛选", t, func() {
+		f, err := New(Options{Statuses: []string{"已发布"}})
+		So(err, ShouldBeNil)
+		So(titles(f), ShouldResemble, []string{"查询宠物"})
+
+		f, _ = New(Options{ExcludeStatuses: []string{"designing"}})
+		So(titles(f), ShouldResemble, []string{"查询宠物", "查询订单", "登录"})
+
+		_, err = New(Options{Statuses: []string{"relesed"}})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("测试按请求方式和路径筛选", t, func() {
+		f, _ := New(Options{Methods: []string{"POST"}})
+		So(titles(f), ShouldResemble, []string{"新建宠物", "登录"})
+
+		f, _ = New(Options{Paths: []string{"^/pet"}, Methods: []string{"get"}})
+		So(titles(f), ShouldResemble, []string{"查询宠物"})
+
+		f, _ = New(Options{ExcludePaths: []string{"^/(pet|store)"}})
+		So(titles(f), ShouldResemble, []string{"登录"})
+	})
+
+	Convey("测试错误的正则表达式", t, func() {
+		_, err := New(Options{Paths: []string{"("}})
+		So(err, ShouldNotBeNil)
+
+		_, err = New(Options{Folders: []string{"[a"}})
+		So(err, ShouldNotBeNil)
+	})
+}
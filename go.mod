@@ -5,8 +5,8 @@ go 1.19
 require (
 	github.com/fatih/color v1.15.0
 	github.com/go-openapi/spec v0.20.8
-	github.com/levigross/grequests v0.0.0-20221222020224-9eee758d18d5
 	github.com/smartystreets/goconvey v1.7.2
+	github.com/tj/go-spin v1.1.0
 	github.com/urfave/cli/v2 v2.25.7
 	golang.org/x/tools v0.4.0
 )
@@ -16,7 +16,6 @@ require (
 	github.com/go-openapi/jsonpointer v0.19.5 // indirect
 	github.com/go-openapi/jsonreference v0.20.0 // indirect
 	github.com/go-openapi/swag v0.19.15 // indirect
-	github.com/google/go-querystring v1.0.0 // indirect
 	github.com/gopherjs/gopherjs v0.0.0-20181017120253-0766667cb4d1 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/jtolds/gls v4.20.0+incompatible // indirect
@@ -26,10 +25,8 @@ require (
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/smartystreets/assertions v1.2.0 // indirect
 	github.com/stretchr/testify v1.8.1 // indirect
-	github.com/tj/go-spin v1.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
 	golang.org/x/mod v0.7.0 // indirect
-	golang.org/x/net v0.3.0 // indirect
 	golang.org/x/sys v0.10.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
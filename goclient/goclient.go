@@ -0,0 +1,467 @@
+// Package goclient 根据接口文档生成 Go 客户端代码，供其他服务调用。
+//
+// 请求体和响应直接使用注释中引用的原始类型（从类型所在的包导入），路径参数和查询参数生成为方法的参数。
+package goclient
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/whaios/apigo/parser"
+	"go/format"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPackage 默认的包名
+const DefaultPackage = "client"
+
+//go:embed runtime.go.tmpl
+var runtime string
+
+// reservedNames 生成的代码中使用的名称，参数和导入的包不能使用
+var reservedNames = map[string]bool{
+	"c": true, "ctx": true, "body": true, "out": true, "err": true, "urlPath": true, "query": true,
+	"form": true, "files": true, "contentType": true,
+	"bytes": true, "context": true, "json": true, "fmt": true, "io": true, "multipart": true,
+	"http": true, "url": true, "strings": true, "time": true,
+}
+
+type generator struct {
+	imports   map[string]string // 导入的包路径 -> 包名
+	stdlib    map[string]bool   // 导入的标准库
+	pkgNames  map[string]bool   // 已使用的包名
+	typeNames map[string]bool   // 已使用的类型名
+	methods   map[string]bool   // 已使用的方法名
+	types     []string          // 生成的类型，如组合响应的结构体
+}
+
+// Generate 生成 Go 客户端代码，pkgName 为生成代码的包名。
+//
+//	请求头（如登录凭证）通过 Client.Header 统一设置；没有导出的类型、internal 包和 main 包中的类型使用 map[string]interface{} 等通用类型。
+func Generate(pkgName string, items []parser.ApiItem) ([]byte, error) {
+	if pkgName == "" {
+		pkgName = DefaultPackage
+	}
+	if !token.IsIdentifier(pkgName) {
+		return nil, fmt.Errorf("包名 %q 不是有效的 Go 标识符", pkgName)
+	}
+	g := &generator{
+		imports:   make(map[string]string),
+		stdlib:    map[string]bool{"bytes": true, "context": true, "encoding/json": true, "fmt": true, "io": true, "mime/multipart": true, "net/http": true, "net/url": true, "strings": true},
+		pkgNames:  make(map[string]bool),
+		typeNames: map[string]bool{"Client": true, "Error": true},
+		// 方法名不能与 Client 的字段重名
+		methods: map[string]bool{"BaseURL": true, "HTTPClient": true, "Header": true},
+	}
+	methods := make([]string, 0, len(items))
+	for i := range items {
+		methods = append(methods, g.method(&items[i]))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by apigo. DO NOT EDIT.\n\n")
+	sb.WriteString("// Package " + pkgName + " 由 apigo 根据接口注释生成的接口客户端。\n")
+	sb.WriteString("package " + pkgName + "\n\n")
+	sb.WriteString("import (\n")
+	for _, p := range sortedKeys(g.stdlib) {
+		sb.WriteString(strconv.Quote(p) + "\n")
+	}
+	sb.WriteString("\n")
+	for _, p := range sortedKeys(g.imports) {
+		sb.WriteString(g.imports[p] + " " + strconv.Quote(p) + "\n")
+	}
+	sb.WriteString(")\n\n")
+	sb.WriteString(runtime)
+	for _, t := range g.types {
+		sb.WriteString("\n" + t)
+	}
+	for _, m := range methods {
+		sb.WriteString("\n" + m)
+	}
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码出错: %s", err)
+	}
+	return src, nil
+}
+
+// importAlias 导入类型所在的包，返回包名，重名时加上序号
+func (g *generator) importAlias(pkgPath string) string {
+	if alias, ok := g.imports[pkgPath]; ok {
+		return alias
+	}
+	base := identifier(path.Base(pkgPath), false)
+	alias := base
+	for i := 2; reservedNames[alias] || g.pkgNames[alias]; i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	g.imports[pkgPath] = alias
+	g.pkgNames[alias] = true
+	return alias
+}
+
+// namedType 导出的 Go 类型，如：model.Pet；没有类型名、类型没有导出或所在的包不能导入时返回空字符串
+func (g *generator) namedType(schema *spec.Schema) string {
+	fullName := parser.SchemaGetTypeFullName(schema)
+	i := strings.LastIndex(fullName, ".")
+	if i <= 0 || !token.IsExported(fullName[i+1:]) || !importable(fullName[:i]) || parser.SchemaIsMainPackage(schema) {
+		return ""
+	}
+	return g.importAlias(fullName[:i]) + "." + fullName[i+1:]
+}
+
+// importable 生成的客户端是否可以导入该包，internal 包只能被同一模块中的部分包导入，不导入
+func importable(pkgPath string) bool {
+	for _, elem := range strings.Split(pkgPath, "/") {
+		if elem == "internal" {
+			return false
+		}
+	}
+	return true
+}
+
+// goType 数据结构对应的 Go 类型
+func (g *generator) goType(schema *spec.Schema) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if named := g.namedType(schema); named != "" {
+		return named
+	}
+	if len(schema.AllOf) > 0 {
+		return "json.RawMessage"
+	}
+	tpe := ""
+	if len(schema.Type) > 0 {
+		tpe = schema.Type[0]
+	}
+	switch tpe {
+	case parser.ARRAY:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(schema.Items.Schema)
+	case parser.OBJECT:
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return "map[string]" + g.goType(schema.AdditionalProperties.Schema)
+		}
+		return "map[string]interface{}"
+	case parser.STRING:
+		if schema.Format == "date-time" {
+			g.stdlib["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case parser.INTEGER:
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case parser.NUMBER:
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case parser.BOOLEAN:
+		return "bool"
+	}
+	return "interface{}"
+}
+
+// responseType 响应的类型，组合类型生成为嵌入原始类型的结构体；返回的 pointer 表示方法返回指针
+func (g *generator) responseType(schema *spec.Schema, methodName string) (tpe string, pointer bool) {
+	if named := g.namedType(schema); named != "" {
+		return named, true
+	}
+	if len(schema.AllOf) == 0 {
+		return g.goType(schema), false
+	}
+
+	name := uniqueName(g.typeNames, methodName+"Response")
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("// %s %s 方法的响应\ntype %s struct {\n", name, methodName, name))
+	for i := range schema.AllOf {
+		sub := &schema.AllOf[i]
+		if named := g.namedType(sub); named != "" {
+			sb.WriteString(named + "\n")
+			continue
+		}
+		for _, propName := range parser.SchemaPropertyNames(sub) {
+			prop := sub.Properties[propName]
+			if prop.Description != "" {
+				sb.WriteString("// " + identifier(propName, true) + " " + prop.Description + "\n")
+			}
+			sb.WriteString(fmt.Sprintf("%s %s `json:%q`\n", identifier(propName, true), g.goType(&prop), propName+",omitempty"))
+		}
+	}
+	sb.WriteString("}\n")
+	g.types = append(g.types, sb.String())
+	return name, true
+}
+
+// method 生成接口对应的方法，参数依次为：ctx、路径参数、查询参数、表单参数、请求体
+func (g *generator) method(apiItem *parser.ApiItem) string {
+	name := uniqueName(g.methods, methodName(apiItem))
+	// 先生成响应类型，返回语句使用生成时确定的类型名
+	result, pointer := g.result(apiItem, name)
+	args := []string{"ctx context.Context"}
+	var body strings.Builder
+	argNames := make(map[string]bool)
+	argName := func(paramName string) string {
+		n := identifier(paramName, false)
+		if reservedNames[n] || token.IsKeyword(n) || g.pkgNames[n] {
+			n += "Param"
+		}
+		return uniqueName(argNames, n)
+	}
+
+	// 路径参数
+	urlPath := strconv.Quote(apiItem.Path)
+	for _, param := range pathParams(apiItem) {
+		arg := argName(param.Name)
+		args = append(args, arg+" "+paramGoType(param.Type))
+		urlPath = strings.Replace(urlPath, "{"+param.Name+"}", `" + url.PathEscape(fmt.Sprint(`+arg+`)) + "`, 1)
+	}
+	urlPath = strings.TrimSuffix(strings.ReplaceAll(urlPath, ` + ""`, ""), ` + "`)
+	body.WriteString("urlPath := " + urlPath + "\n")
+
+	// 查询参数，选填的参数为 nil 时不发送
+	query := "nil"
+	if len(apiItem.Parameters.Query) > 0 {
+		query = "query"
+		body.WriteString("query := make(url.Values)\n")
+		for _, param := range apiItem.Parameters.Query {
+			arg := argName(param.Name)
+			args = append(args, arg+" "+optionalType(param))
+			body.WriteString(setValue("query", param, arg))
+		}
+	}
+
+	// 请求体
+	reqBody, contentType := "nil", `""`
+	switch {
+	case len(apiItem.Parameters.FormData) > 0:
+		reqBody, contentType = "body", "contentType"
+		body.WriteString("form := make(url.Values)\n")
+		files := make([]string, 0)
+		for _, param := range apiItem.Parameters.FormData {
+			arg := argName(param.Name)
+			if param.Type == "file" {
+				args = append(args, arg+" io.Reader")
+				files = append(files, strconv.Quote(param.Name)+": "+arg)
+				continue
+			}
+			args = append(args, arg+" "+optionalType(param))
+			body.WriteString(setValue("form", param, arg))
+		}
+		if apiItem.Parameters.BodyType == parser.BodyTypeFormData {
+			body.WriteString("files := map[string]io.Reader{" + strings.Join(files, ", ") + "}\n")
+			body.WriteString("body, contentType, err := multipartBody(form, files)\nif err != nil {\nreturn " + errorReturn(result, pointer) + "\n}\n")
+		} else {
+			body.WriteString("body, contentType := strings.NewReader(form.Encode()), " + strconv.Quote(parser.BodyTypeFormUrlEncoded) + "\n")
+		}
+	case apiItem.Parameters.JsonSchema != nil:
+		tpe := g.goType(apiItem.Parameters.JsonSchema)
+		if g.namedType(apiItem.Parameters.JsonSchema) != "" {
+			tpe = "*" + tpe
+		}
+		args = append(args, "body "+tpe)
+		reqBody, contentType = "reqBody", strconv.Quote(parser.BodyTypeJSON)
+		body.WriteString("reqBody, err := jsonBody(body)\nif err != nil {\nreturn " + errorReturn(result, pointer) + "\n}\n")
+	}
+
+	// 响应
+	call := fmt.Sprintf("c.do(ctx, %q, urlPath, %s, %s, %s, %%s)", strings.ToUpper(apiItem.Method), query, reqBody, contentType)
+	switch {
+	case result == "":
+		body.WriteString("return " + fmt.Sprintf(call, "nil") + "\n")
+	default:
+		body.WriteString("out := new(" + result + ")\n")
+		body.WriteString("if err := " + fmt.Sprintf(call, "out") + "; err != nil {\nreturn " + errorReturn(result, pointer) + "\n}\n")
+		if pointer {
+			body.WriteString("return out, nil\n")
+		} else {
+			body.WriteString("return *out, nil\n")
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// " + name + " " + apiItem.Title + "\n")
+	if desc := strings.TrimSpace(apiItem.Description); desc != "" {
+		sb.WriteString("//\n")
+		for _, line := range strings.Split(desc, "\n") {
+			sb.WriteString("// " + line + "\n")
+		}
+	}
+	sb.WriteString("//\n//\t" + apiItem.Endpoint() + "\n")
+	sb.WriteString(fmt.Sprintf("func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), returns(result, pointer)))
+	sb.WriteString(body.String())
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// result 第一个成功响应的类型，没有响应数据时返回空字符串
+func (g *generator) result(apiItem *parser.ApiItem, methodName string) (string, bool) {
+	for _, resp := range apiItem.Responses {
+		if resp.Code >= 200 && resp.Code < 300 {
+			if resp.JsonSchema == nil {
+				return "", false
+			}
+			return g.responseType(resp.JsonSchema, methodName)
+		}
+	}
+	return "", false
+}
+
+// returns 方法的返回值，result 为响应的类型，pointer 表示返回指针
+func returns(result string, pointer bool) string {
+	switch {
+	case result == "":
+		return "error"
+	case pointer:
+		return "(*" + result + ", error)"
+	default:
+		return "(" + result + ", error)"
+	}
+}
+
+// errorReturn 出错时的返回语句
+func errorReturn(result string, pointer bool) string {
+	switch {
+	case result == "":
+		return "err"
+	case pointer || strings.HasPrefix(result, "[]") || strings.HasPrefix(result, "map[") ||
+		result == "interface{}" || result == "json.RawMessage":
+		return "nil, err"
+	default:
+		return "*new(" + result + "), err"
+	}
+}
+
+// setValue 设置查询参数或表单参数，选填的参数为 nil 时不设置
+func setValue(values string, param parser.Parameter, arg string) string {
+	value := "fmt.Sprint(" + arg + ")"
+	if paramGoType(param.Type) == "string" {
+		value = arg
+	}
+	switch {
+	case param.Type == parser.ARRAY:
+		return fmt.Sprintf("for _, v := range %s {\n%s.Add(%q, v)\n}\n", arg, values, param.Name)
+	case !param.Required:
+		value = strings.Replace(value, arg, "*"+arg, 1)
+		return fmt.Sprintf("if %s != nil {\n%s.Set(%q, %s)\n}\n", arg, values, param.Name, value)
+	default:
+		return fmt.Sprintf("%s.Set(%q, %s)\n", values, param.Name, value)
+	}
+}
+
+// optionalType 选填参数使用指针类型，nil 表示不发送
+func optionalType(param parser.Parameter) string {
+	tpe := paramGoType(param.Type)
+	if param.Required || strings.HasPrefix(tpe, "[]") {
+		return tpe
+	}
+	return "*" + tpe
+}
+
+// paramGoType 参数的 Go 类型，参数类型可能是 Go 类型名称，如：int64
+func paramGoType(tpe string) string {
+	switch tpe {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "bool", "string":
+		return tpe
+	case parser.INTEGER:
+		return "int64"
+	case parser.NUMBER:
+		return "float64"
+	case parser.BOOLEAN:
+		return "bool"
+	case parser.ARRAY:
+		return "[]string"
+	}
+	return "string"
+}
+
+// pathParams 路径参数，路径中没有注释的参数作为必填的字符串参数
+func pathParams(apiItem *parser.ApiItem) []parser.Parameter {
+	params := make([]parser.Parameter, 0)
+	for _, name := range parser.PathParams(apiItem.Path) {
+		param := parser.Parameter{Name: name, Type: parser.STRING, Required: true}
+		for _, p := range apiItem.Parameters.Path {
+			if p.Name == name {
+				param = p
+				break
+			}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// methodName 使用接口注释所在的 Go 方法名；没有时根据请求方式和路径生成，如：GET /pet/{petId} -> GetPetPetId
+func methodName(apiItem *parser.ApiItem) string {
+	if apiItem.Source != nil && apiItem.Source.Func != "" {
+		return identifier(apiItem.Source.Func, true)
+	}
+	return identifier(apiItem.Method+" "+apiItem.Path, true)
+}
+
+// identifier 转换为 Go 标识符，exported 为 true 时首字母大写，如：pet_id -> PetId
+func identifier(s string, exported bool) string {
+	var sb strings.Builder
+	upper := exported
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_' && sb.Len() == 0:
+			if upper {
+				r = []rune(strings.ToUpper(string(r)))[0]
+			}
+			sb.WriteRune(r)
+			upper = false
+		case r >= '0' && r <= '9':
+			if sb.Len() == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+			upper = false
+		default:
+			upper = sb.Len() > 0 || exported
+		}
+	}
+	name := sb.String()
+	if name == "" {
+		name = "v"
+		if exported {
+			name = "V"
+		}
+	}
+	if !exported {
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+	return name
+}
+
+// sortedKeys 排序后的 key
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// uniqueName 重名时加上序号
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
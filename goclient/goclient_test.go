@@ -0,0 +1,229 @@
+package goclient
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixtureModule 生成的客户端引用的类型所在的模块
+var fixtureModule = map[string]string{
+	"go.mod":               "module petshop\n\ngo 1.19\n",
+	"comm/comm.go":         "package comm\n\ntype HttpCode struct {\n\tErrcode int32 `json:\"errcode\"`\n}\n",
+	"model/model.go":       "package model\n\ntype Pet struct {\n\tName string `json:\"name\"`\n}\n",
+	"other/model/model.go": "package model\n\ntype Pet struct{}\n",
+}
+
+// fixtureImporter 从源码检查模块中的包，标准库使用默认的导入器
+type fixtureImporter struct {
+	fset   *token.FileSet
+	dir    string
+	module string
+	pkgs   map[string]*types.Package
+	std    types.Importer
+}
+
+func (imp *fixtureImporter) Import(path string) (*types.Package, error) {
+	if !strings.HasPrefix(path, imp.module+"/") {
+		return imp.std.Import(path)
+	}
+	if pkg, ok := imp.pkgs[path]; ok {
+		return pkg, nil
+	}
+	pkg, err := imp.check(path, filepath.Join(imp.dir, strings.TrimPrefix(path, imp.module+"/")))
+	if err != nil {
+		return nil, err
+	}
+	imp.pkgs[path] = pkg
+	return pkg, nil
+}
+
+// check 检查目录中所有 go 文件的类型
+func (imp *fixtureImporter) check(path, dir string) (*types.Package, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		f, err := goparser.ParseFile(imp.fset, name, nil, goparser.AllErrors)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: imp}
+	return conf.Check(path, imp.fset, files, nil)
+}
+
+// typeCheck 将生成的客户端放到测试模块中，检查生成的代码能否通过编译器的类型检查
+func typeCheck(t *testing.T, src []byte) error {
+	dir := t.TempDir()
+	for name, content := range fixtureModule {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "client"), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client", "client.go"), src, 0644); err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	imp := &fixtureImporter{fset: fset, dir: dir, module: "petshop", pkgs: make(map[string]*types.Package), std: importer.Default()}
+	_, err := imp.check("petshop/client", filepath.Join(dir, "client"))
+	return err
+}
+
+func TestGenerate(t *testing.T) {
+	httpCode := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{parser.OBJECT},
+		Properties: map[string]spec.Schema{"errcode": *spec.Int32Property()},
+	}}, "petshop/comm.HttpCode")
+	pet := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{parser.OBJECT},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()},
+	}}, "petshop/model.Pet")
+	otherPet := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{parser.OBJECT}}}, "petshop/other/model.Pet")
+	items := []parser.ApiItem{
+		{
+			Title:       "查询宠物详情",
+			Description: "指定id查询宠物详情",
+			Method:      "get",
+			Path:        "/pet/{petId}",
+			Parameters: parser.Parameters{
+				Path:  []parser.Parameter{{Name: "petId", Type: "int", Required: true}},
+				Query: []parser.Parameter{{Name: "lang", Type: parser.STRING}, {Name: "type", Type: "int", Required: true}},
+			},
+			Responses: []*parser.Response{{Code: 200, JsonSchema: spec.ComposedSchema(*httpCode, spec.Schema{SchemaProps: spec.SchemaProps{
+				Type:       []string{parser.OBJECT},
+				Properties: map[string]spec.Schema{"data": *pet},
+			}})}},
+			Source: &parser.Source{Func: "GetPet"},
+		},
+		{
+			Title:  "上传宠物照片",
+			Method: "post",
+			Path:   "/pet/{petId}/photo",
+			Parameters: parser.Parameters{
+				BodyType: parser.BodyTypeFormData,
+				FormData: []parser.Parameter{{Name: "remark", Type: parser.STRING}, {Name: "file", Type: "file", Required: true}},
+			},
+			Responses: []*parser.Response{{Code: 200, JsonSchema: spec.DateTimeProperty()}},
+		},
+		{
+			Title:  "同步宠物",
+			Method: "put",
+			Path:   "/pet/sync",
+			Parameters: parser.Parameters{
+				BodyType:   parser.BodyTypeJSON,
+				JsonSchema: otherPet,
+			},
+			Responses: []*parser.Response{{Code: 204}},
+		},
+	}
+
+	Convey("测试生成 Go 客户端", t, func() {
+		src, err := Generate("", items)
+		So(err, ShouldBeNil)
+		code := string(src)
+		So(typeCheck(t, src), ShouldBeNil)
+
+		So(code, ShouldStartWith, "// Code generated by apigo. DO NOT EDIT.")
+		So(code, ShouldContainSubstring, "package client\n")
+		So(code, ShouldContainSubstring, `	comm "petshop/comm"
+	model "petshop/model"
+	model2 "petshop/other/model"`)
+		So(code, ShouldContainSubstring, `type GetPetResponse struct {
+	comm.HttpCode
+	Data model.Pet `+"`"+`json:"data,omitempty"`+"`"+`
+}`)
+		So(code, ShouldContainSubstring, `// GetPet 查询宠物详情
+//
+// 指定id查询宠物详情
+//
+//	GET /pet/{petId}
+func (c *Client) GetPet(ctx context.Context, petId int, lang *string, typeParam int) (*GetPetResponse, error) {
+	urlPath := "/pet/" + url.PathEscape(fmt.Sprint(petId))
+	query := make(url.Values)
+	if lang != nil {
+		query.Set("lang", *lang)
+	}
+	query.Set("type", fmt.Sprint(typeParam))`)
+
+		// 路径中没有注释的参数、上传文件和非结构体响应
+		So(code, ShouldContainSubstring, `func (c *Client) PostPetPetIdPhoto(ctx context.Context, petId string, remark *string, file io.Reader) (time.Time, error) {`)
+		So(code, ShouldContainSubstring, `files := map[string]io.Reader{"file": file}`)
+		So(code, ShouldContainSubstring, `return *new(time.Time), err`)
+		So(code, ShouldContainSubstring, `return *out, nil`)
+
+		// 没有响应数据
+		So(code, ShouldContainSubstring, `func (c *Client) PutPetSync(ctx context.Context, body *model2.Pet) error {`)
+		So(code, ShouldContainSubstring, `return c.do(ctx, "PUT", urlPath, nil, reqBody, "application/json", nil)`)
+	})
+
+	Convey("测试方法名与 Client 的字段重名、不能导入的类型", t, func() {
+		secret := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"token": *spec.StringProperty()},
+		}}, "petshop/internal/auth.Secret")
+		config := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{parser.OBJECT}}}, "petshop.Config")
+		parser.SchemaSetMainPackage(config)
+		src, err := Generate("", []parser.ApiItem{
+			{
+				Title:  "请求头",
+				Method: "get",
+				Path:   "/header",
+				Responses: []*parser.Response{{Code: 200, JsonSchema: spec.ComposedSchema(*httpCode, spec.Schema{SchemaProps: spec.SchemaProps{
+					Type:       []string{parser.OBJECT},
+					Properties: map[string]spec.Schema{"data": *secret},
+				}})}},
+				Source: &parser.Source{Func: "Header"},
+			},
+			{
+				Title:      "配置",
+				Method:     "put",
+				Path:       "/config",
+				Parameters: parser.Parameters{BodyType: parser.BodyTypeJSON, JsonSchema: config},
+				Responses:  []*parser.Response{{Code: 200, JsonSchema: secret}},
+				Source:     &parser.Source{Func: "BaseURL"},
+			},
+		})
+		So(err, ShouldBeNil)
+		So(typeCheck(t, src), ShouldBeNil)
+		code := string(src)
+		So(code, ShouldContainSubstring, "func (c *Client) Header2(ctx context.Context) (*Header2Response, error) {")
+		So(code, ShouldContainSubstring, "func (c *Client) BaseURL2(ctx context.Context, body map[string]interface{}) (map[string]interface{}, error) {")
+		So(code, ShouldNotContainSubstring, "internal")
+	})
+
+	Convey("测试无效的包名", t, func() {
+		_, err := Generate("my-client", items)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestIdentifier(t *testing.T) {
+	Convey("测试转换为 Go 标识符", t, func() {
+		So(identifier("pet_id", true), ShouldEqual, "PetId")
+		So(identifier("page-size", false), ShouldEqual, "pageSize")
+		So(identifier("get /pet/{petId}", true), ShouldEqual, "GetPetPetId")
+		So(identifier("2fa", false), ShouldEqual, "_2fa")
+		So(identifier("", true), ShouldEqual, "V")
+	})
+}
@@ -2,11 +2,12 @@ package goscanner
 
 import (
 	"go/ast"
+	"go/token"
 	"path/filepath"
 	"strings"
 )
 
-func NewAstFile(p *Package, path string, file *ast.File) *AstFile {
+func NewAstFile(p *Package, path string, fset *token.FileSet, file *ast.File) *AstFile {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		absPath = path
@@ -16,16 +17,18 @@ func NewAstFile(p *Package, path string, file *ast.File) *AstFile {
 		pkg:     p,
 		path:    path,
 		absPath: absPath,
+		fset:    fset,
 		file:    file,
 	}
 }
 
 // AstFile Go 源码文件信息.
 type AstFile struct {
-	pkg     *Package  // Go 源码文件所在包
-	path    string    // Go 源码文件名称
-	absPath string    // Go 源码文件全名称
-	file    *ast.File // Go 源码文件
+	pkg     *Package       // Go 源码文件所在包
+	path    string         // Go 源码文件名称
+	absPath string         // Go 源码文件全名称
+	fset    *token.FileSet // 解析该文件时使用的文件集，用于获取代码位置
+	file    *ast.File      // Go 源码文件
 }
 
 // PkgId 获取 go 源码文件所属包
@@ -84,3 +87,11 @@ func (f *AstFile) DotImports() []string {
 func (f *AstFile) File() *ast.File {
 	return f.file
 }
+
+// Position 获取代码在文件中的位置
+func (f *AstFile) Position(pos token.Pos) token.Position {
+	if f.fset == nil || !pos.IsValid() {
+		return token.Position{Filename: f.path}
+	}
+	return f.fset.Position(pos)
+}
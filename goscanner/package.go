@@ -2,7 +2,9 @@ package goscanner
 
 import (
 	"go/ast"
+	"go/token"
 	"strings"
+	"sync"
 )
 
 func newPackage(id string) *Package {
@@ -13,15 +15,18 @@ func newPackage(id string) *Package {
 	}
 }
 
-// Package 管理 go 包下面的文件和类型
+// Package 管理 go 包下面的文件和类型，可以并发使用
 type Package struct {
+	mu    sync.RWMutex
 	id    string                  // 包完整名称
 	files map[string]*AstFile     // 包下的go代码文件，key=absPath
 	types map[string]*AstTypeSpec // 使用到的所有类型，key=类型唯一名称（包名+类型名 type.Id）
 }
 
-func (p *Package) AddFile(path string, file *ast.File) *AstFile {
-	astFile := NewAstFile(p, path, file)
+func (p *Package) AddFile(path string, fset *token.FileSet, file *ast.File) *AstFile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	astFile := NewAstFile(p, path, fset, file)
 	p.files[astFile.AbsPath()] = astFile
 	return astFile
 }
@@ -32,12 +37,34 @@ func (p *Package) AddType(astFile *AstFile, typeSpec *ast.TypeSpec) *AstTypeSpec
 		File:     astFile,
 		TypeSpec: typeSpec,
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.types[astTypeSpec.Id()] = astTypeSpec
 	return astTypeSpec
 }
 
+// RemoveFile 移除指定文件及其中申明的类型，返回被移除的文件，没有找到返回 nil
+func (p *Package) RemoveFile(absPath string) *AstFile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	astFile, ok := p.files[absPath]
+	if !ok {
+		return nil
+	}
+	delete(p.files, absPath)
+	for id, tpe := range p.types {
+		if tpe.File == astFile {
+			delete(p.types, id)
+		}
+	}
+	return astFile
+}
+
 // GetType 获取类型
 func (p *Package) GetType(typeId string) *AstTypeSpec {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.types[typeId]
 }
 
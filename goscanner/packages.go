@@ -4,6 +4,9 @@ import (
 	"github.com/whaios/apigo/log"
 	"go/ast"
 	"go/token"
+	"path/filepath"
+	"sort"
+	"sync"
 )
 
 func newPackages() *Packages {
@@ -13,23 +16,57 @@ func newPackages() *Packages {
 	}
 }
 
-// Packages 管理扫描到的所有包和文件
+// Packages 管理扫描到的所有包和文件，可以并发使用
 type Packages struct {
+	mu    sync.RWMutex
 	files map[string]*AstFile // 使用到的所有go代码文件，key=absPath
 	pkgs  map[string]*Package // 使用到的所有go包，key=pkgId
 }
 
 // ParseFile 解析go代码文件中的类型
-func (p *Packages) ParseFile(pkgId, path string, file *ast.File) *AstFile {
+func (p *Packages) ParseFile(pkgId, path string, fset *token.FileSet, file *ast.File) *AstFile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	pkg, ok := p.pkgs[pkgId]
 	if !ok {
 		pkg = newPackage(pkgId)
 		p.pkgs[pkg.id] = pkg
 	}
-
-	astFile := pkg.AddFile(path, file)
+	astFile := addFile(pkg, path, fset, file)
 	p.files[astFile.absPath] = astFile
+	return astFile
+}
+
+// ParsePackage 解析同一个包中的所有go代码文件。
+//
+//	先在新的包中采集所有文件的类型，再一次性加入，其他协程不会查找到只有部分文件的包。
+func (p *Packages) ParsePackage(pkgId string, paths []string, fset *token.FileSet, files []*ast.File) {
+	pkg := newPackage(pkgId)
+	astFiles := make([]*AstFile, 0, len(files))
+	for i, file := range files {
+		astFiles = append(astFiles, addFile(pkg, paths[i], fset, file))
+	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if old, ok := p.pkgs[pkgId]; ok {
+		// 已经有该包时（如根目录下的包），在原来的包中添加文件
+		for i, file := range files {
+			astFile := addFile(old, paths[i], fset, file)
+			p.files[astFile.absPath] = astFile
+		}
+		return
+	}
+	p.pkgs[pkgId] = pkg
+	for _, astFile := range astFiles {
+		p.files[astFile.absPath] = astFile
+	}
+}
+
+// addFile 在包中添加文件，并采集文件中申明的类型
+func addFile(pkg *Package, path string, fset *token.FileSet, file *ast.File) *AstFile {
+	astFile := pkg.AddFile(path, fset, file)
 	for _, decl := range astFile.file.Decls {
 		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
 			for _, spec := range genDecl.Specs {
@@ -44,7 +81,48 @@ func (p *Packages) ParseFile(pkgId, path string, file *ast.File) *AstFile {
 	return astFile
 }
 
+// RemoveFile 移除指定文件及其中申明的类型，返回被移除的文件，没有找到返回 nil
+func (p *Packages) RemoveFile(absPath string) *AstFile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	astFile, ok := p.files[absPath]
+	if !ok {
+		return nil
+	}
+	delete(p.files, absPath)
+	astFile.pkg.RemoveFile(absPath)
+	return astFile
+}
+
+// GetFile 获取解析过的指定文件，没有找到返回 nil
+func (p *Packages) GetFile(absPath string) *AstFile {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.files[absPath]
+}
+
+// Dirs 获取所有文件所在的目录
+func (p *Packages) Dirs() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	dirs := make([]string, 0)
+	exists := make(map[string]bool)
+	for absPath := range p.files {
+		dir := filepath.Dir(absPath)
+		if !exists[dir] {
+			exists[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
 // GetPkg 获取解析过的指定包，没有找到返回nil
 func (p *Packages) GetPkg(pkgId string) *Package {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.pkgs[pkgId]
 }
@@ -14,14 +14,20 @@ package goscanner
 import (
 	"fmt"
 	"github.com/whaios/apigo/log"
+	"go/ast"
 	"go/build"
 	"go/parser"
 	"go/token"
 	"golang.org/x/tools/go/packages"
 	"io/fs"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // New go代码解析器
@@ -30,6 +36,7 @@ func New() *Scanner {
 		mode:     parser.ParseComments,
 		files:    make([]*AstFile, 0),
 		packages: newPackages(),
+		loaded:   make(map[string]bool),
 	}
 }
 
@@ -41,9 +48,17 @@ type Scanner struct {
 	mode     parser.Mode
 	files    []*AstFile // 收集目录中的go文件，按字母顺序排序
 	packages *Packages  // 管理扫描到的所有包和类型
+
+	loadMu sync.Mutex      // 同一时间只加载一批外部包，避免重复加载
+	loaded map[string]bool // 加载过的外部包，包括没有代码文件的包
+
+	dirsMu   sync.Mutex           // 保护 rootDirs
+	rootDirs map[string]time.Time // 根目录及其所有子目录的修改时间，key=absPath
 }
 
 // Scan 指定要扫描的代码目录，并开始收集代码，解析类型。
+//
+//	多个文件并发解析，扫描到的文件仍按遍历目录的顺序排列。
 func (p *Scanner) Scan(dir string) error {
 	log.UpdateSpinner("获取Go包名")
 
@@ -57,37 +72,265 @@ func (p *Scanner) Scan(dir string) error {
 
 	// 按字母顺序遍历根目录的文件树（包括树中的每个目录和文件）。
 	// 如果rootDir使用相对路径，那么 WalkDirFunc 回调函数中的 id 参数也为相对路径。
+	paths := make([]string, 0)
 	err = filepath.WalkDir(p.rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		// 跳过目录
 		if d.IsDir() {
+			if path != p.rootDir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		// 跳过非go业务代码文件
-		if filepath.Ext(path) != ".go" ||
-			strings.HasSuffix(path, "_test.go") {
+		if !isGoSourceFile(path) {
 			return nil
 		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	files, err := p.parseFiles(paths)
+	if err != nil {
+		return err
+	}
+	// 按遍历顺序采集类型，保证结果稳定
+	for _, f := range files {
+		p.files = append(p.files, p.packages.ParseFile(f.pkgId, f.path, f.fset, f.file))
+	}
+	return nil
+}
+
+// parsedFile 解析后还没有采集类型的 go 代码文件
+type parsedFile struct {
+	pkgId, path string
+	fset        *token.FileSet
+	file        *ast.File
+}
+
+// parseFiles 使用多个协程并发解析根目录下的 go 代码文件，返回结果与 paths 的顺序一致，有多个错误时返回第一个文件的错误
+func (p *Scanner) parseFiles(paths []string) ([]*parsedFile, error) {
+	files := make([]*parsedFile, len(paths))
+	errs := make([]error, len(paths))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, path := range paths {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			files[i], errs[i] = p.parseFile(path)
+		}(i, path)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// parseFile 解析根目录下的指定 go 代码文件
+func (p *Scanner) parseFile(path string) (*parsedFile, error) {
+	// 根据根包名，计算go文件所在的包
+	relPath, err := filepath.Rel(p.rootDir, path)
+	if err != nil {
+		return nil, err
+	}
+	pkgId := filepath.ToSlash(filepath.Dir(filepath.Join(p.rootPkg, relPath)))
 
-		// 根据根包名，计算go文件所在的包
-		relPath, err := filepath.Rel(p.rootDir, path)
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, path, nil, p.mode)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFile{pkgId: pkgId, path: path, fset: fset, file: astFile}, nil
+}
+
+// UpdateFile 文件发生变化后，重新解析该文件，并替换该文件及其中申明的类型。
+//
+//	文件已被删除时，只移除该文件。不在根目录下，且没有解析过的文件会被忽略。
+//	重新解析出错时（如编辑中的语法错误）保留上次解析的结果。
+//	出现新文件时，之前加载过但没有代码文件的包会在下次查找类型时重新加载。
+func (p *Scanner) UpdateFile(path string) error {
+	if !isGoSourceFile(path) {
+		return nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err = os.Stat(absPath); os.IsNotExist(err) {
+		log.Debug("移除文件: %s", path)
+		p.packages.RemoveFile(absPath)
+		p.removeFile(absPath)
+		return nil
+	}
+
+	// 根目录下的文件
+	if relPath, ok := p.relPath(absPath); ok {
+		f, err := p.parseFile(filepath.Join(p.rootDir, relPath))
 		if err != nil {
 			return err
 		}
-		pkgId := filepath.ToSlash(filepath.Dir(filepath.Join(p.rootPkg, relPath)))
+		if p.packages.GetFile(absPath) == nil {
+			p.resetEmptyLoaded()
+		}
+		p.packages.RemoveFile(absPath)
+		p.removeFile(absPath)
+		p.files = append(p.files, p.packages.ParseFile(f.pkgId, f.path, f.fset, f.file))
+		// 保持和遍历目录时相同的顺序
+		sort.SliceStable(p.files, func(i, j int) bool {
+			return pathLess(p.files[i].path, p.files[j].path)
+		})
+		return nil
+	}
 
-		astFile, err := parser.ParseFile(token.NewFileSet(), path, nil, p.mode)
+	// 外部包中解析过的文件
+	if oldFile := p.packages.GetFile(absPath); oldFile != nil {
+		fset := token.NewFileSet()
+		astFile, err := parser.ParseFile(fset, oldFile.path, nil, p.mode)
 		if err != nil {
 			return err
 		}
+		p.packages.RemoveFile(absPath)
+		p.packages.ParseFile(oldFile.PkgId(), oldFile.path, fset, astFile)
+		return nil
+	}
+	// 没有解析过的外部文件，可能是之前为空或不存在的包中新增的文件
+	p.resetEmptyLoaded()
+	return nil
+}
+
+// resetEmptyLoaded 清除加载过但没有代码文件的包，下次查找类型时重新加载
+func (p *Scanner) resetEmptyLoaded() {
+	p.loadMu.Lock()
+	defer p.loadMu.Unlock()
+	for pkgId := range p.loaded {
+		if p.packages.GetPkg(pkgId) == nil {
+			delete(p.loaded, pkgId)
+		}
+	}
+}
+
+// removeFile 从扫描到的文件中移除指定文件
+func (p *Scanner) removeFile(absPath string) {
+	for i, f := range p.files {
+		if f.absPath == absPath {
+			p.files = append(p.files[:i], p.files[i+1:]...)
+			return
+		}
+	}
+}
+
+// relPath 获取文件相对于根目录的路径，不在根目录下返回 false
+func (p *Scanner) relPath(absPath string) (string, bool) {
+	absRoot, err := filepath.Abs(p.rootDir)
+	if err != nil {
+		return "", false
+	}
+	relPath, err := filepath.Rel(absRoot, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return relPath, true
+}
+
+// isGoSourceFile 是否为go业务代码文件（不包括测试代码）
+func isGoSourceFile(path string) bool {
+	return filepath.Ext(path) == ".go" && !strings.HasSuffix(path, "_test.go")
+}
+
+// skipDir 是否跳过不属于业务代码的目录：隐藏目录、vendor 和 testdata
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata"
+}
+
+// pathLess 按目录逐级比较文件路径，与 filepath.WalkDir 的遍历顺序一致
+func pathLess(a, b string) bool {
+	as, bs := strings.Split(filepath.ToSlash(a), "/"), strings.Split(filepath.ToSlash(b), "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// Dirs 获取需要监听变化的目录：根目录及其所有子目录，以及解析过的外部包所在目录。
+//
+//	只有目录的修改时间变化（新增、删除或重命名了其中的文件或子目录）时才重新遍历根目录。
+func (p *Scanner) Dirs() []string {
+	p.dirsMu.Lock()
+	if p.rootDirsChanged() {
+		p.rootDirs = walkDirs(p.rootDir)
+	}
+	dirs := make([]string, 0, len(p.rootDirs))
+	for dir := range p.rootDirs {
+		dirs = append(dirs, dir)
+	}
+	p.dirsMu.Unlock()
+	sort.Strings(dirs)
+
+	exists := make(map[string]bool)
+	for _, dir := range dirs {
+		exists[dir] = true
+	}
+	for _, dir := range p.packages.Dirs() {
+		if !exists[dir] {
+			exists[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
 
-		astFileInfo := p.packages.ParseFile(pkgId, path, astFile)
-		p.files = append(p.files, astFileInfo)
+// rootDirsChanged 上次遍历后根目录及其子目录是否有变化
+func (p *Scanner) rootDirsChanged() bool {
+	if p.rootDirs == nil {
+		return true
+	}
+	for dir, modTime := range p.rootDirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkDirs 遍历获取根目录及其所有子目录的修改时间，跳过的目录同 Scan
+func walkDirs(rootDir string) map[string]time.Time {
+	dirs := make(map[string]time.Time)
+	_ = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != rootDir && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if absPath, err := filepath.Abs(path); err == nil {
+			dirs[absPath] = info.ModTime()
+		}
 		return nil
 	})
-	return err
+	return dirs
 }
 
 // FileCount 获取扫描到的文件个数
@@ -151,7 +394,7 @@ func (p *Scanner) GetType(name string, astFile *AstFile) (*AstTypeSpec, error) {
 		// 还没有解析过此包
 		if pkg == nil {
 			// 加载指定包下的代码文件，并解析其中的类型
-			if err := p.parseExternalPackage(pkgId); err != nil {
+			if err := p.LoadPackages(pkgId); err != nil {
 				return nil, err
 			}
 			// 解析后还是没有此包信息，可能此包下没有代码文件
@@ -168,19 +411,39 @@ func (p *Scanner) GetType(name string, astFile *AstFile) (*AstTypeSpec, error) {
 	return nil, nil
 }
 
-// parseExternalPackage 加载指定包下的代码文件，并解析其中的类型
-func (p *Scanner) parseExternalPackage(pkg string) error {
+// LoadPackages 使用一次 packages.Load 批量加载多个外部包的代码文件，并解析其中的类型。
+//
+//	已经解析或加载过的包会被跳过，可以在多个协程中调用。
+func (p *Scanner) LoadPackages(pkgIds ...string) error {
+	p.loadMu.Lock()
+	defer p.loadMu.Unlock()
+
+	patterns := make([]string, 0, len(pkgIds))
+	for _, pkgId := range pkgIds {
+		if p.loaded[pkgId] || p.packages.GetPkg(pkgId) != nil {
+			continue
+		}
+		patterns = append(patterns, pkgId)
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	log.Debug("加载外部包: %s", strings.Join(patterns, ", "))
+
 	cfg := &packages.Config{
 		Dir:  p.rootDir,
 		Mode: packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedCompiledGoFiles,
 	}
-	pkgs, err := packages.Load(cfg, pkg)
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
 		return err
 	}
+	for _, pattern := range patterns {
+		p.loaded[pattern] = true
+	}
 	for _, pkg := range pkgs {
-		for i, astFile := range pkg.Syntax {
-			p.packages.ParseFile(pkg.ID, pkg.CompiledGoFiles[i], astFile)
+		if len(pkg.Syntax) > 0 {
+			p.packages.ParsePackage(pkg.ID, pkg.CompiledGoFiles[:len(pkg.Syntax)], pkg.Fset, pkg.Syntax)
 		}
 	}
 	return nil
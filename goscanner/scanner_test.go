@@ -2,7 +2,10 @@ package goscanner
 
 import (
 	. "github.com/smartystreets/goconvey/convey"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -89,3 +92,167 @@ func TestParser_Parse(t *testing.T) {
 		}
 	})
 }
+
+func TestScanner_UpdateFile(t *testing.T) {
+	Convey("测试文件变化后重新解析", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module tmpmod\n\ngo 1.18\n"), 0644), ShouldBeNil)
+		aFile := filepath.Join(dir, "a.go")
+		So(os.WriteFile(aFile, []byte("package tmpmod\n\ntype A struct{}\n"), 0644), ShouldBeNil)
+
+		p := New()
+		So(p.Scan(dir), ShouldBeNil)
+		So(p.FileCount(), ShouldEqual, 1)
+		file := p.GetFile("a.go")
+		astType, err := p.GetType("A", file)
+		So(err, ShouldBeNil)
+		So(astType, ShouldNotBeNil)
+
+		// ---- 修改文件中申明的类型 --------------------
+		So(os.WriteFile(aFile, []byte("package tmpmod\n\ntype B struct{}\n"), 0644), ShouldBeNil)
+		So(p.UpdateFile(aFile), ShouldBeNil)
+		So(p.FileCount(), ShouldEqual, 1)
+		file = p.GetFile("a.go")
+		astType, err = p.GetType("A", file)
+		So(err, ShouldBeNil)
+		So(astType, ShouldBeNil)
+		astType, err = p.GetType("B", file)
+		So(err, ShouldBeNil)
+		So(astType, ShouldNotBeNil)
+		So(astType.Id(), ShouldEqual, "tmpmod.B")
+
+		// ---- 新增文件 --------------------
+		So(os.WriteFile(filepath.Join(dir, "0.go"), []byte("package tmpmod\n\ntype C struct{}\n"), 0644), ShouldBeNil)
+		So(p.UpdateFile(filepath.Join(dir, "0.go")), ShouldBeNil)
+		So(p.FileCount(), ShouldEqual, 2)
+		So(p.Files()[0].Name(), ShouldEqual, "0.go")
+
+		// ---- 删除文件 --------------------
+		So(os.Remove(aFile), ShouldBeNil)
+		So(p.UpdateFile(aFile), ShouldBeNil)
+		So(p.FileCount(), ShouldEqual, 1)
+		astType, err = p.GetType("B", p.GetFile("0.go"))
+		So(err, ShouldBeNil)
+		So(astType, ShouldBeNil)
+	})
+}
+
+func TestScanner_LoadPackages(t *testing.T) {
+	Convey("测试批量加载外部包", t, func() {
+		p := New()
+		So(p.Scan("../example/goparser/target"), ShouldBeNil)
+
+		// 并发解析后文件顺序保持不变
+		other := New()
+		So(other.Scan("../example/goparser/target"), ShouldBeNil)
+		So(other.FileCount(), ShouldEqual, p.FileCount())
+		for i, file := range p.Files() {
+			So(other.Files()[i].Path(), ShouldEqual, file.Path())
+		}
+
+		So(p.packages.GetPkg("goparser/dotpkg"), ShouldBeNil)
+		So(p.LoadPackages("goparser/dotpkg", "goparser/unusepkg", "goparser/target/pkga"), ShouldBeNil)
+		So(p.packages.GetPkg("goparser/dotpkg"), ShouldNotBeNil)
+		So(p.packages.GetPkg("goparser/unusepkg"), ShouldNotBeNil)
+		So(p.loaded, ShouldResemble, map[string]bool{"goparser/dotpkg": true, "goparser/unusepkg": true})
+
+		// 加载过的包不再重复加载
+		So(p.LoadPackages("goparser/dotpkg"), ShouldBeNil)
+		astType, err := p.GetType("DotPkg", p.GetFile("a.go"))
+		So(err, ShouldBeNil)
+		So(astType.Id(), ShouldEqual, "goparser/dotpkg.DotPkg")
+	})
+}
+
+func TestScanner_UpdateFileNewPackage(t *testing.T) {
+	Convey("测试之前为空的外部包新增文件后重新加载", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module shop\n\ngo 1.19\n"), 0644), ShouldBeNil)
+		So(os.MkdirAll(filepath.Join(dir, "api"), 0755), ShouldBeNil)
+		So(os.MkdirAll(filepath.Join(dir, "model"), 0755), ShouldBeNil)
+		src := "package api\n\nimport \"shop/model\"\n\ntype A struct {\n\tPet model.Pet\n}\n"
+		So(os.WriteFile(filepath.Join(dir, "api", "a.go"), []byte(src), 0644), ShouldBeNil)
+
+		p := New()
+		So(p.Scan(filepath.Join(dir, "api")), ShouldBeNil)
+		astType, err := p.GetType("model.Pet", p.GetFile("a.go"))
+		So(err, ShouldBeNil)
+		So(astType, ShouldBeNil)
+		So(p.loaded["shop/model"], ShouldBeTrue)
+
+		modelFile := filepath.Join(dir, "model", "model.go")
+		So(os.WriteFile(modelFile, []byte("package model\n\ntype Pet struct{}\n"), 0644), ShouldBeNil)
+		So(p.UpdateFile(modelFile), ShouldBeNil)
+		astType, err = p.GetType("model.Pet", p.GetFile("a.go"))
+		So(err, ShouldBeNil)
+		So(astType, ShouldNotBeNil)
+		So(astType.Id(), ShouldEqual, "shop/model.Pet")
+	})
+}
+
+func TestScanner_GetTypeConcurrent(t *testing.T) {
+	Convey("测试并发查找外部包中的类型，不会查找到只有部分文件的包", t, func() {
+		dir := t.TempDir()
+		write := func(name, content string) {
+			path := filepath.Join(dir, name)
+			So(os.MkdirAll(filepath.Dir(path), 0755), ShouldBeNil)
+			So(os.WriteFile(path, []byte(content), 0644), ShouldBeNil)
+		}
+		write("go.mod", "module shop\n\ngo 1.19\n")
+		write("api/a.go", "package api\n\nimport \"shop/model\"\n\nvar _ model.Z\n")
+		for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "z"} {
+			write("model/"+name+".go", "package model\n\ntype "+strings.ToUpper(name)+" struct{}\n")
+		}
+
+		for i := 0; i < 5; i++ {
+			p := New()
+			So(p.Scan(filepath.Join(dir, "api")), ShouldBeNil)
+			file := p.GetFile("a.go")
+
+			var wg sync.WaitGroup
+			found := make([]bool, 8)
+			for j := range found {
+				wg.Add(1)
+				go func(j int) {
+					defer wg.Done()
+					astType, err := p.GetType("model.Z", file)
+					found[j] = err == nil && astType != nil
+				}(j)
+			}
+			wg.Wait()
+			for _, ok := range found {
+				So(ok, ShouldBeTrue)
+			}
+		}
+	})
+}
+
+func TestScanner_Dirs(t *testing.T) {
+	Convey("测试获取需要监听的目录，跳过隐藏目录、vendor 和 testdata", t, func() {
+		dir := t.TempDir()
+		write := func(name, content string) {
+			path := filepath.Join(dir, name)
+			So(os.MkdirAll(filepath.Dir(path), 0755), ShouldBeNil)
+			So(os.WriteFile(path, []byte(content), 0644), ShouldBeNil)
+		}
+		write("go.mod", "module shop\n\ngo 1.19\n")
+		write("a.go", "package shop\n\ntype A struct{}\n")
+		write("model/model.go", "package model\n\ntype Pet struct{}\n")
+		write(".git/x.go", "package x\n")
+		write("vendor/x/x.go", "package x\n")
+		write("testdata/x.go", "package x\n")
+
+		p := New()
+		So(p.Scan(dir), ShouldBeNil)
+		So(p.FileCount(), ShouldEqual, 2)
+		root, err := filepath.Abs(dir)
+		So(err, ShouldBeNil)
+		So(p.Dirs(), ShouldResemble, []string{root, filepath.Join(root, "model")})
+
+		// 新增子目录后，上级目录的修改时间变化，重新遍历
+		So(os.MkdirAll(filepath.Join(dir, "model", "v2"), 0755), ShouldBeNil)
+		So(p.Dirs(), ShouldResemble, []string{root, filepath.Join(root, "model"), filepath.Join(root, "model", "v2")})
+		So(os.Remove(filepath.Join(dir, "model", "v2")), ShouldBeNil)
+		So(p.Dirs(), ShouldResemble, []string{root, filepath.Join(root, "model")})
+	})
+}
@@ -0,0 +1,256 @@
+// Package markdown 将接口文档按目录层级生成 Markdown 文档，可以生成单个文件，也可以按目录拆分为多个文件，模板使用 text/template，可以自定义。
+package markdown
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/sample"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplate 默认模板，自定义模板时可以参考
+//
+//go:embed template.md
+var DefaultTemplate string
+
+// 按目录拆分时生成的文件
+const (
+	IndexFile = "README.md" // 索引页，包含没有目录的接口和各目录文件的链接
+	FileExt   = ".md"
+)
+
+// Document 模板的数据
+type Document struct {
+	Title   string    // 文档标题
+	Apis    []*Api    // 没有目录的接口
+	Folders []*Folder // 一级目录
+	Links   []*Link   // 按目录拆分时，索引页中各目录文件的链接
+}
+
+// Link 目录文件的链接
+type Link struct {
+	Name string // 目录名称，多级目录使用 / 连接
+	Path string // 相对于索引页的文件路径
+}
+
+// Page 按目录拆分后的单个 Markdown 文件
+type Page struct {
+	Path string    // 文件路径，按目录层级生成，如：宠物商城/宠物管理.md
+	Doc  *Document // 文件内容
+}
+
+// Folder 目录，Level 为标题的级别，一级目录为 2
+type Folder struct {
+	Name    string
+	Level   int
+	Apis    []*Api
+	Folders []*Folder
+}
+
+// Api 接口
+type Api struct {
+	*parser.ApiItem
+	Level       int                // 标题的级别
+	Body        []parser.Parameter // JSON 请求体展开后的字段，嵌套字段使用 . 连接，数组使用 []
+	BodyExample string             // JSON 请求体示例
+	Responses   []*Response
+}
+
+// Response 响应
+type Response struct {
+	Code    int
+	Name    string
+	Fields  []parser.Parameter // 响应数据展开后的字段
+	Example string             // 响应数据示例
+}
+
+// NewDocument 按目录层级整理接口
+func NewDocument(title string, items []parser.ApiItem) *Document {
+	doc := &Document{Title: title}
+	for i := range items {
+		apiItem := &items[i]
+		level := 2
+		apis := &doc.Apis
+		folders := &doc.Folders
+		for _, name := range apiItem.Folder {
+			var sub *Folder
+			for _, f := range *folders {
+				if f.Name == name {
+					sub = f
+					break
+				}
+			}
+			if sub == nil {
+				sub = &Folder{Name: name, Level: level}
+				*folders = append(*folders, sub)
+			}
+			apis, folders = &sub.Apis, &sub.Folders
+			level++
+		}
+		*apis = append(*apis, newApi(apiItem, level))
+	}
+	return doc
+}
+
+// Pages 按目录拆分文档：每个有接口的目录生成一个文件，只包含该目录中的接口，子目录的接口在子目录的文件中；
+// 没有目录的接口和所有目录文件的链接放在索引页 README.md 中，索引页为第一个文件。
+func Pages(doc *Document) []*Page {
+	index := &Document{Title: doc.Title, Apis: doc.Apis}
+	pages := []*Page{{Path: IndexFile, Doc: index}}
+	var walk func(parent parser.Folder, folders []*Folder)
+	walk = func(parent parser.Folder, folders []*Folder) {
+		for _, f := range folders {
+			folder := parent.Join(f.Name)
+			if len(f.Apis) > 0 {
+				page := &Page{Path: folder.FilePath(FileExt), Doc: &Document{Title: folder.String()}}
+				// 每个文件中的接口都是二级标题
+				for _, api := range f.Apis {
+					cp := *api
+					cp.Level = 2
+					page.Doc.Apis = append(page.Doc.Apis, &cp)
+				}
+				index.Links = append(index.Links, &Link{Name: folder.String(), Path: page.Path})
+				pages = append(pages, page)
+			}
+			walk(folder, f.Folders)
+		}
+	}
+	walk(nil, doc.Folders)
+	return pages
+}
+
+func newApi(apiItem *parser.ApiItem, level int) *Api {
+	api := &Api{ApiItem: apiItem, Level: level}
+	if schema := apiItem.Parameters.JsonSchema; schema != nil {
+		api.Body = Fields(schema)
+		api.BodyExample = sample.JSON(schema)
+	}
+	for _, resp := range apiItem.Responses {
+		r := &Response{Code: resp.Code, Name: resp.Name}
+		if resp.JsonSchema != nil {
+			r.Fields = Fields(resp.JsonSchema)
+			r.Example = sample.JSON(resp.JsonSchema)
+		}
+		api.Responses = append(api.Responses, r)
+	}
+	return api
+}
+
+// Fields 将数据结构展开为二维的字段列表，嵌套字段使用 . 连接，数组元素使用 []，如：data.tags[].name
+func Fields(schema *spec.Schema) []parser.Parameter {
+	fields := make([]parser.Parameter, 0)
+	appendFields(&fields, "", schema, 0)
+	return fields
+}
+
+func appendFields(fields *[]parser.Parameter, prefix string, schema *spec.Schema, depth int) {
+	if depth > parser.SchemaMaxDepth {
+		return
+	}
+	if parser.SchemaType(schema) == parser.ARRAY && schema.Items != nil && schema.Items.Schema != nil {
+		appendFields(fields, prefix+"[]", schema.Items.Schema, depth+1)
+		return
+	}
+	props, required := properties(schema)
+	if prefix != "" {
+		prefix += "."
+	}
+	for _, name := range parser.SchemaPropertyNames(schema) {
+		prop := props[name]
+		*fields = append(*fields, parser.Parameter{
+			Name:        prefix + name,
+			Type:        typeName(&prop),
+			Required:    required[name],
+			Example:     example(&prop),
+			Description: prop.Description,
+		})
+		appendFields(fields, prefix+name, &prop, depth+1)
+	}
+}
+
+// properties 对象的属性，合并 allOf 中的属性
+func properties(schema *spec.Schema) (map[string]spec.Schema, map[string]bool) {
+	props := make(map[string]spec.Schema)
+	required := make(map[string]bool)
+	for i := range schema.AllOf {
+		subProps, subRequired := properties(&schema.AllOf[i])
+		for name, prop := range subProps {
+			props[name] = prop
+			required[name] = subRequired[name]
+		}
+	}
+	for name, prop := range schema.Properties {
+		props[name] = prop
+	}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	return props, required
+}
+
+// typeName 数据类型，数组包含元素类型，如：array[string]
+func typeName(schema *spec.Schema) string {
+	tpe := parser.SchemaType(schema)
+	if tpe == parser.ARRAY && schema.Items != nil && schema.Items.Schema != nil {
+		return tpe + "[" + typeName(schema.Items.Schema) + "]"
+	}
+	return tpe
+}
+
+// example 字段的示例值，字符串原样输出，其他类型使用 JSON 格式
+func example(schema *spec.Schema) string {
+	switch v := schema.Example.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := json.Marshal(schema.Example)
+	if err != nil {
+		return fmt.Sprint(schema.Example)
+	}
+	return string(data)
+}
+
+// Funcs 模板中可以使用的函数
+var Funcs = template.FuncMap{
+	"heading": heading,
+	"cell":    cell,
+	"quote":   quote,
+	"upper":   strings.ToUpper,
+}
+
+// heading 指定级别的标题前缀，最多 6 级
+func heading(level int) string {
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
+
+// cell 转义表格单元格中的 | 和换行
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// quote 引用多行文本
+func quote(s string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ")
+}
+
+// Render 使用模板生成 Markdown 文档，tmpl 为空时使用默认模板
+func Render(w io.Writer, tmpl string, doc *Document) error {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New("markdown").Funcs(Funcs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("解析 Markdown 模板出错: %s", err)
+	}
+	return t.Execute(w, doc)
+}
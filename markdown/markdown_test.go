@@ -0,0 +1,115 @@
+package markdown
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"strings"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	Convey("测试展开数据结构", t, func() {
+		tag := spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"name": *spec.StringProperty().WithDescription("标签名称")},
+		}}
+		data := spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:     []string{parser.OBJECT},
+			Required: []string{"id"},
+			Properties: map[string]spec.Schema{
+				"id":    *spec.Int64Property().WithExample(10),
+				"tags":  *spec.ArrayProperty(&tag),
+				"extra": *spec.MapProperty(spec.StringProperty()).WithExample(map[string]interface{}{"color": "white"}),
+			},
+		}}
+		parser.SchemaSetPropertiesOrders(&data, []string{"tags", "id", "extra"})
+		resp := spec.ComposedSchema(*spec.Int32Property(), spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"data": data},
+		}})
+		resp.AllOf[0] = spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"errcode": *spec.Int32Property()},
+		}}
+
+		So(Fields(resp), ShouldResemble, []parser.Parameter{
+			{Name: "data", Type: parser.OBJECT},
+			{Name: "data.tags", Type: "array[object]"},
+			{Name: "data.tags[].name", Type: parser.STRING, Description: "标签名称"},
+			{Name: "data.id", Type: parser.INTEGER, Required: true, Example: "10"},
+			{Name: "data.extra", Type: parser.OBJECT, Example: `{"color":"white"}`},
+			{Name: "errcode", Type: parser.INTEGER},
+		})
+	})
+}
+
+func TestRender(t *testing.T) {
+	items := []parser.ApiItem{
+		{Title: "登录", Method: "post", Path: "/login"},
+		{
+			Title:       "查询宠物详情",
+			Folder:      parser.Folder{"宠物商城", "宠物管理"},
+			Status:      parser.StatusReleased,
+			Description: "指定id查询宠物详情",
+			Remark:      "本接口需要登录\n管理员可以查询所有宠物",
+			Method:      "get",
+			Path:        "/pet/{petId}",
+			Parameters: parser.Parameters{
+				Path: []parser.Parameter{{Name: "petId", Type: "int", Required: true, Example: "1", Description: "宠物 id"}},
+			},
+			Responses: []*parser.Response{{Code: 200, Name: "成功", JsonSchema: spec.StringProperty()}},
+		},
+	}
+
+	Convey("测试按目录层级生成文档", t, func() {
+		doc := NewDocument("宠物商城", items)
+		So(len(doc.Apis), ShouldEqual, 1)
+		So(doc.Apis[0].Level, ShouldEqual, 2)
+		So(doc.Folders[0].Name, ShouldEqual, "宠物商城")
+		So(doc.Folders[0].Folders[0].Level, ShouldEqual, 3)
+		So(doc.Folders[0].Folders[0].Apis[0].Level, ShouldEqual, 4)
+
+		var buf strings.Builder
+		So(Render(&buf, "", doc), ShouldBeNil)
+		md := buf.String()
+		So(md, ShouldStartWith, "# 宠物商城\n")
+		So(md, ShouldContainSubstring, "\n## 登录\n")
+		So(md, ShouldContainSubstring, "\n### 宠物管理\n")
+		So(md, ShouldContainSubstring, "\n#### 查询宠物详情\n")
+		So(md, ShouldContainSubstring, "\n#### 查询宠物详情\n\n状态：已发布\n\n`GET /pet/{petId}`")
+		So(md, ShouldContainSubstring, "`GET /pet/{petId}`")
+		So(md, ShouldContainSubstring, "> 本接口需要登录\n> 管理员可以查询所有宠物\n")
+		So(md, ShouldContainSubstring, "| petId | int | 是 | 1 | 宠物 id |")
+		So(md, ShouldContainSubstring, "**响应 200** 成功")
+		So(md, ShouldContainSubstring, "```json\n\"string\"\n```")
+	})
+
+	Convey("测试按目录拆分为多个文件", t, func() {
+		pages := Pages(NewDocument("宠物商城", append(items, parser.ApiItem{Title: "宠物列表", Folder: parser.Folder{"宠物商城"}, Method: "get", Path: "/pet"})))
+		So(pages, ShouldHaveLength, 3)
+		So(pages[0].Path, ShouldEqual, IndexFile)
+		So(pages[1].Path, ShouldEqual, "宠物商城.md")
+		So(pages[2].Path, ShouldEqual, "宠物商城/宠物管理.md")
+		So(pages[2].Doc.Title, ShouldEqual, "宠物商城/宠物管理")
+		So(pages[2].Doc.Apis[0].Level, ShouldEqual, 2)
+
+		var buf strings.Builder
+		So(Render(&buf, "", pages[0].Doc), ShouldBeNil)
+		So(buf.String(), ShouldStartWith, "# 宠物商城\n\n- [宠物商城](<宠物商城.md>)\n- [宠物商城/宠物管理](<宠物商城/宠物管理.md>)\n\n## 登录\n")
+
+		buf.Reset()
+		So(Render(&buf, "", pages[2].Doc), ShouldBeNil)
+		So(buf.String(), ShouldStartWith, "# 宠物商城/宠物管理\n\n## 查询宠物详情\n")
+		So(buf.String(), ShouldNotContainSubstring, "## 登录")
+	})
+
+	Convey("测试自定义模板", t, func() {
+		var buf strings.Builder
+		err := Render(&buf, `{{ range .Apis }}{{ upper .Method }} {{ .Path }}{{ end }}`, NewDocument("", items))
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, "POST /login")
+
+		So(Render(&buf, `{{ .Unknown `, NewDocument("", items)), ShouldNotBeNil)
+	})
+}
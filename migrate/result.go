@@ -0,0 +1,116 @@
+package migrate
+
+import (
+	"fmt"
+	"github.com/whaios/apigo/goscanner"
+	"os"
+	"strings"
+)
+
+// Result 转换结果
+type Result struct {
+	Files []*File // 需要修改或有警告的文件
+}
+
+// File 文件中需要修改的注释
+type File struct {
+	Changes  []*Change  // 按行号排序
+	Warnings []*Warning // 无法转换的注释，需要手动修改
+
+	file *goscanner.AstFile
+}
+
+// Path 文件路径
+func (f *File) Path() string {
+	return f.file.Path()
+}
+
+// Change 将一行注释替换为一行或多行 apigo 注释
+type Change struct {
+	Line int      // 行号
+	Old  string   // 原注释
+	New  []string // 转换后的注释
+
+	offset, end int // 原注释在文件中的位置
+}
+
+// Warning 无法转换的注释
+type Warning struct {
+	Line    int
+	Func    string
+	Message string
+}
+
+// Changed 需要修改的注释行数
+func (r *Result) Changed() int {
+	n := 0
+	for _, f := range r.Files {
+		n += len(f.Changes)
+	}
+	return n
+}
+
+// Diff 以 unified diff 格式输出修改内容，每行注释为一个块
+func (r *Result) Diff() string {
+	var sb strings.Builder
+	for _, f := range r.Files {
+		if len(f.Changes) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", f.Path(), f.Path()))
+		delta := 0 // 前面的修改增加的行数
+		for _, c := range f.Changes {
+			sb.WriteString(fmt.Sprintf("@@ -%d +%d,%d @@\n", c.Line, c.Line+delta, len(c.New)))
+			sb.WriteString("-" + c.Old + "\n")
+			for _, line := range c.New {
+				sb.WriteString("+" + line + "\n")
+			}
+			delta += len(c.New) - 1
+		}
+	}
+	return sb.String()
+}
+
+// Warnings 以纯文本格式输出无法转换的注释
+func (r *Result) Warnings() string {
+	var sb strings.Builder
+	for _, f := range r.Files {
+		for _, w := range f.Warnings {
+			sb.WriteString(fmt.Sprintf("%s:%d %s() %s\n", f.Path(), w.Line, w.Func, w.Message))
+		}
+	}
+	return sb.String()
+}
+
+// Write 将修改写入文件，同一文件中从后往前替换，避免位置偏移
+func (r *Result) Write() error {
+	for _, f := range r.Files {
+		if len(f.Changes) == 0 {
+			continue
+		}
+		path := f.file.AbsPath()
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for i := len(f.Changes) - 1; i >= 0; i-- {
+			c := f.Changes[i]
+			if c.end > len(src) || string(src[c.offset:c.end]) != c.Old {
+				return fmt.Errorf("文件 %s 已发生变化，请重新执行", path)
+			}
+			// 保持与原注释相同的缩进
+			lineStart := strings.LastIndex(string(src[:c.offset]), "\n") + 1
+			indent := string(src[lineStart:c.offset])
+			text := strings.Join(c.New, "\n"+indent)
+			src = append(src[:c.offset], append([]byte(text), src[c.end:]...)...)
+		}
+		if err = os.WriteFile(path, src, info.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
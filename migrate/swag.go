@@ -0,0 +1,246 @@
+// Package migrate 将其他工具的接口注释转换为 apigo 的注释格式。
+package migrate
+
+import (
+	"fmt"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/parser"
+	"go/ast"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// swag 中请求和响应的 Mime 类型别名对应的 apigo 别名
+var swagMimeAliases = map[string]string{
+	"json":                        parser.MimeAliasJson,
+	"xml":                         parser.MimeAliasXml,
+	"plain":                       parser.MimeAliasRaw,
+	"html":                        parser.MimeAliasHtml,
+	"mpfd":                        parser.MimeAliasFormData,
+	"x-www-form-urlencoded":       parser.MimeAliasFormUrlencoded,
+	"octet-stream":                parser.MimeAliasBinary,
+	parser.BodyTypeJSON:           parser.MimeAliasJson,
+	parser.BodyTypeXML:            parser.MimeAliasXml,
+	parser.BodyTypePlain:          parser.MimeAliasRaw,
+	parser.BodyTypeHTML:           parser.MimeAliasHtml,
+	parser.BodyTypeFormData:       parser.MimeAliasFormData,
+	parser.BodyTypeFormUrlEncoded: parser.MimeAliasFormUrlencoded,
+	parser.BodyTypeOctetStream:    parser.MimeAliasBinary,
+}
+
+// swag 参数中的基础类型
+var swagPrimitiveTypes = map[string]bool{
+	"string": true, "integer": true, "number": true, "boolean": true, "file": true,
+	"int": true, "int32": true, "int64": true, "uint": true, "float32": true, "float64": true, "bool": true,
+}
+
+// @Param [参数名] [参数类型] [数据类型] [必填] ["备注"] [属性]
+var swagParamPattern = regexp.MustCompile(`^(\S+)\s+(\w+)\s+(\S+)\s+(\w+)\s+"([^"]*)"(.*)$`)
+
+// 参数属性中的示例值或默认值，如：example(1)、default(1)
+var swagExamplePattern = regexp.MustCompile(`(?i)(?:example|default)\(([^)]*)\)`)
+
+// @Success [状态码] {[类型]} [数据类型] ["备注"]
+var swagRespPattern = regexp.MustCompile(`^(\d+|default)\s*(?:{(\w+)}\s+(\S+))?\s*(?:"([^"]*)")?\s*$`)
+
+// @Router [路径] [[请求方式]]
+var swagRouterPattern = regexp.MustCompile(`^(\S+)\s+\[(\w+)]`)
+
+// Swag 将 swaggo/swag 的接口注释转换为 apigo 的注释格式。
+//
+//	只转换注释中有 @Router 的方法，没有对应格式的注释（如 @ID、@Security）保持不变。
+func Swag(files []*goscanner.AstFile) *Result {
+	result := &Result{}
+	for _, file := range files {
+		fr := &File{file: file}
+		for _, decl := range file.File().Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Doc == nil || !isSwagOperation(fd.Doc) {
+				continue
+			}
+			for _, comment := range fd.Doc.List {
+				text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+				if !strings.HasPrefix(text, "@") {
+					continue
+				}
+				line := file.Position(comment.Pos()).Line
+				lines, err := convertSwag(text)
+				if err != nil {
+					fr.Warnings = append(fr.Warnings, &Warning{Line: line, Func: fd.Name.Name, Message: err.Error()})
+					continue
+				}
+				if lines == nil {
+					continue
+				}
+				// 保持原注释 @ 之前的格式，如：//	@Summary
+				prefix := comment.Text[:strings.Index(comment.Text, "@")]
+				newLines := make([]string, 0, len(lines))
+				for _, l := range lines {
+					newLines = append(newLines, prefix+l)
+				}
+				fr.Changes = append(fr.Changes, &Change{
+					Line:   line,
+					Old:    comment.Text,
+					New:    newLines,
+					offset: file.Position(comment.Pos()).Offset,
+					end:    file.Position(comment.End()).Offset,
+				})
+			}
+		}
+		if len(fr.Changes) > 0 || len(fr.Warnings) > 0 {
+			result.Files = append(result.Files, fr)
+		}
+	}
+	return result
+}
+
+// isSwagOperation 注释中有 @Router 的方法
+func isSwagOperation(doc *ast.CommentGroup) bool {
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if fields := strings.Fields(text); len(fields) > 0 && strings.EqualFold(fields[0], "@router") {
+			return true
+		}
+	}
+	return false
+}
+
+// convertSwag 将单行 swag 注释转换为 apigo 注释，不需要转换时返回 nil
+func convertSwag(text string) ([]string, error) {
+	tag := strings.Fields(text)[0]
+	value := strings.TrimSpace(text[len(tag):])
+	switch strings.ToLower(tag) {
+	case "@summary":
+		return []string{parser.TagTitle + " " + value}, nil
+	case "@description":
+		return []string{parser.TagDesc + " " + value}, nil
+	case "@tags":
+		tags := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(tags) == 0 {
+			return nil, nil
+		}
+		lines := []string{parser.TagFolder + " " + strings.NewReplacer(`\`, `\\`, "/", `\/`).Replace(tags[0])}
+		if len(tags) > 1 {
+			lines = append(lines, parser.TagTag+" "+strings.Join(tags[1:], " "))
+		}
+		return lines, nil
+	case "@accept":
+		alias, err := swagMime(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{parser.TagBodyType + " " + alias}, nil
+	case "@produce":
+		alias, err := swagMime(value)
+		if err != nil {
+			return nil, err
+		}
+		if alias == parser.MimeAliasFormData || alias == parser.MimeAliasFormUrlencoded {
+			return nil, fmt.Errorf("不支持 %s 响应内容格式: %s", alias, text)
+		}
+		return []string{parser.TagContentType + " " + alias}, nil
+	case "@router":
+		matches := swagRouterPattern.FindStringSubmatch(value)
+		if matches == nil {
+			return nil, fmt.Errorf("无法解析 %s", text)
+		}
+		return []string{parser.TagUrl + " " + strings.ToUpper(matches[2]) + " " + matches[1]}, nil
+	case "@deprecated":
+		return []string{parser.TagStatus + " " + string(parser.StatusDeprecated)}, nil
+	case "@param":
+		line, err := convertSwagParam(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", err, text)
+		}
+		return []string{line}, nil
+	case "@success", "@failure":
+		line, err := convertSwagResp(value, strings.ToLower(tag) == "@success")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", err, text)
+		}
+		return []string{line}, nil
+	}
+	return nil, nil
+}
+
+// swagMime 转换 Mime 类型，有多个时使用第一个
+func swagMime(value string) (string, error) {
+	mime := strings.TrimSpace(strings.Split(value, ",")[0])
+	if alias, ok := swagMimeAliases[strings.ToLower(mime)]; ok {
+		return alias, nil
+	}
+	return "", fmt.Errorf("不支持 %s Mime类型", mime)
+}
+
+// convertSwagParam 转换参数，如：id path int true "宠物 id" example(1) -> path id int true "1" "宠物 id"
+func convertSwagParam(value string) (string, error) {
+	matches := swagParamPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return "", fmt.Errorf("无法解析参数")
+	}
+	name, in, dataType, required, desc, attrs := matches[1], matches[2], matches[3], matches[4], matches[5], matches[6]
+	switch in {
+	case parser.ParamTypePath, parser.ParamTypeQuery, parser.ParamTypeHeader, parser.ParamTypeBody:
+	case "formData":
+		in = parser.ParamTypeForm
+	default:
+		return "", fmt.Errorf("不支持 %s 参数类型", in)
+	}
+	if strings.HasPrefix(dataType, "[]") {
+		return "", fmt.Errorf("不支持数组类型 %s", dataType)
+	}
+
+	// 结构体
+	if in == parser.ParamTypeBody || !swagPrimitiveTypes[dataType] {
+		if swagPrimitiveTypes[dataType] {
+			return "", fmt.Errorf("请求体不支持基础类型 %s", dataType)
+		}
+		return fmt.Sprintf("%s %s %s{}", parser.TagParam, in, dataType), nil
+	}
+
+	example := ""
+	if m := swagExamplePattern.FindStringSubmatch(attrs); m != nil {
+		example = strings.Trim(m[1], `"'`)
+	}
+	return fmt.Sprintf(`%s %s %s %s %s "%s" "%s"`, parser.TagParam, in, name, parser.TransToValidSchemeType(dataType), strings.ToLower(required),
+		strings.ReplaceAll(example, `"`, `'`), desc), nil
+}
+
+// convertSwagResp 转换响应，如：200 {object} model.Pet "成功" -> 200 "成功" model.Pet{}
+//
+//	没有备注的 200 成功响应转换为 @success。
+func convertSwagResp(value string, success bool) (string, error) {
+	matches := swagRespPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return "", fmt.Errorf("无法解析响应")
+	}
+	code, kind, dataType, desc := matches[1], matches[2], matches[3], matches[4]
+	if code == "default" {
+		return "", fmt.Errorf("不支持 default 响应")
+	}
+	switch kind {
+	case "":
+		return "", fmt.Errorf("没有响应数据类型")
+	case "array":
+		return "", fmt.Errorf("不支持数组类型的响应")
+	case parser.STRING, parser.INTEGER, parser.NUMBER, parser.BOOLEAN:
+		return "", fmt.Errorf("不支持基础类型 %s 的响应", kind)
+	}
+	if strings.Contains(dataType, "[]") {
+		return "", fmt.Errorf("不支持数组类型 %s", dataType)
+	}
+	if !strings.HasSuffix(dataType, "}") {
+		dataType += "{}"
+	}
+
+	if success && code == "200" && desc == "" {
+		return parser.TagSuccess + " " + dataType, nil
+	}
+	if desc == "" {
+		statusCode, _ := strconv.Atoi(code)
+		desc = http.StatusText(statusCode)
+	}
+	return fmt.Sprintf(`%s %s "%s" %s`, parser.TagResp, code, desc, dataType), nil
+}
@@ -0,0 +1,137 @@
+package migrate
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/goscanner"
+	"github.com/whaios/apigo/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const swagHandlerGo = `package handler
+
+// Pet 宠物
+type Pet struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+// HttpCode 通用响应
+type HttpCode struct {
+	Errcode int ` + "`json:\"errcode\"`" + `
+}
+
+// GetPet godoc
+//
+//	@Summary		查询宠物详情
+//	@Description	指定id查询宠物详情
+//	@Tags			宠物商城,pet
+//	@Produce		json
+//	@Param			petId	path		int	true	"宠物 id"	example(1)
+//	@Success		200		{object}	HttpCode{data=Pet}
+//	@Failure		404		{object}	HttpCode	"没有找到"
+//	@Failure		500
+//	@ID				get-pet
+//	@Router			/pet/{petId} [get]
+func GetPet() {
+}
+
+// CreatePet godoc
+// @Summary 新建宠物
+// @Accept mpfd
+// @Param name formData string true "宠物名" default(Kitty)
+// @Param photo formData file false "照片"
+// @Success 201 {object} Pet "已创建"
+// @Deprecated
+// @Router /pet [post]
+func CreatePet() {
+}
+
+// ListPets 没有 @Router 的方法不转换
+// @Summary 宠物列表
+func ListPets() {
+}
+`
+
+func TestSwag(t *testing.T) {
+	Convey("测试转换 swag 注释", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module legacy\n\ngo 1.19\n"), 0644), ShouldBeNil)
+		file := filepath.Join(dir, "handler.go")
+		So(os.WriteFile(file, []byte(swagHandlerGo), 0644), ShouldBeNil)
+		scanner := goscanner.New()
+		So(scanner.Scan(dir), ShouldBeNil)
+
+		result := Swag(scanner.Files())
+		So(result.Files, ShouldHaveLength, 1)
+		So(result.Files[0].Warnings, ShouldHaveLength, 1)
+		So(result.Files[0].Warnings[0].Line, ShouldEqual, 22)
+		So(result.Changed(), ShouldEqual, 15)
+		So(result.Diff(), ShouldContainSubstring, `@@ -17 +17,2 @@
+-//	@Tags			宠物商城,pet
++//	@folder 宠物商城
++//	@tag pet
+@@ -18 +19,1 @@
+-//	@Produce		json
++//	@contenttype json
+`)
+
+		So(result.Write(), ShouldBeNil)
+		src, err := os.ReadFile(file)
+		So(err, ShouldBeNil)
+		So(string(src), ShouldContainSubstring, `//	@param path petId integer true "1" "宠物 id"
+//	@success HttpCode{data=Pet}
+//	@resp 404 "没有找到" HttpCode{}
+//	@Failure		500
+//	@ID				get-pet
+//	@url GET /pet/{petId}
+`)
+		So(string(src), ShouldContainSubstring, `// @title 新建宠物
+// @bodytype form-data
+// @param form name string true "Kitty" "宠物名"
+// @param form photo file false "" "照片"
+// @resp 201 "已创建" Pet{}
+// @status deprecated
+// @url POST /pet
+`)
+		So(string(src), ShouldContainSubstring, "// ListPets 没有 @Router 的方法不转换\n// @Summary 宠物列表\n")
+
+		// 转换后的注释可以被解析
+		p := parser.NewParser()
+		_, err = p.Scan(dir)
+		So(err, ShouldBeNil)
+		items, err := p.Parse()
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 2)
+		So(items[0].Name(), ShouldEqual, "宠物商城/查询宠物详情")
+		So(items[0].Responses, ShouldHaveLength, 2)
+		So(items[1].Status, ShouldEqual, parser.StatusDeprecated)
+		So(items[1].Parameters.BodyType, ShouldEqual, parser.BodyTypeFormData)
+	})
+}
+
+func TestConvertSwagResp(t *testing.T) {
+	Convey("测试转换 swag 响应", t, func() {
+		cases := []struct {
+			In, Out string
+		}{
+			{`200 {object} model.Pet`, `@success model.Pet{}`},
+			{`200 {object} model.Pet "成功"`, `@resp 200 "成功" model.Pet{}`},
+			{`400 {object} comm.HttpCode`, `@resp 400 "Bad Request" comm.HttpCode{}`},
+		}
+		for _, c := range cases {
+			out, err := convertSwagResp(c.In, true)
+			So(err, ShouldBeNil)
+			So(out, ShouldEqual, c.Out)
+		}
+
+		_, err := convertSwagResp(`200 {array} model.Pet`, true)
+		So(err, ShouldNotBeNil)
+		_, err = convertSwagResp(`400 {string} string`, true)
+		So(err, ShouldNotBeNil)
+		_, err = convertSwagResp(`200 {integer} int "数量"`, true)
+		So(err, ShouldNotBeNil)
+		_, err = convertSwagResp(`default {object} model.Pet`, false)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,228 @@
+// Package mock 根据解析出的 API 接口文档启动模拟服务，返回示例响应数据。
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/sample"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 指定要返回的响应状态码，没有指定时返回文档中最小的 2xx 状态码，没有 2xx 时返回第一个响应。
+const (
+	HeaderStatus = "X-Apigo-Mock-Status" // 请求头
+	QueryStatus  = "__status"            // 查询参数
+)
+
+// New 创建模拟服务，为每个接口注册 请求方式+路径 的路由，路径中的 {param} 匹配任意一段路径。
+func New(items []parser.ApiItem) *Server {
+	s := &Server{routes: make([]*route, 0, len(items))}
+	for i := range items {
+		s.routes = append(s.routes, newRoute(&items[i]))
+	}
+	// 静态路径优先于参数路径匹配
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		return s.routes[i].static > s.routes[j].static
+	})
+	return s
+}
+
+// Server 模拟服务
+type Server struct {
+	routes []*route
+}
+
+type route struct {
+	item     *parser.ApiItem
+	segments []string
+	static   int // 静态路径段的个数
+}
+
+func newRoute(item *parser.ApiItem) *route {
+	r := &route{item: item, segments: splitPath(item.Path)}
+	for _, seg := range r.segments {
+		if !isParam(seg) {
+			r.static++
+		}
+	}
+	return r
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+// match 匹配请求路径，返回路径参数
+func (r *route) match(method string, segments []string) (map[string]string, bool) {
+	if !strings.EqualFold(r.item.Method, method) || len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range r.segments {
+		if isParam(seg) {
+			params[seg[1:len(seg)-1]] = segments[i]
+			continue
+		}
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// 允许前端页面跨域访问
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	segments := splitPath(r.URL.Path)
+	for _, rt := range s.routes {
+		if pathParams, ok := rt.match(r.Method, segments); ok {
+			s.serveItem(w, r, rt.item, pathParams)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("没有找到接口 %s %s", r.Method, r.URL.Path), nil)
+}
+
+func (s *Server) serveItem(w http.ResponseWriter, r *http.Request, item *parser.ApiItem, pathParams map[string]string) {
+	if missing := missingParams(r, item, pathParams); len(missing) > 0 {
+		writeError(w, http.StatusBadRequest, "缺少必填参数", missing)
+		return
+	}
+
+	resp, err := selectResponse(r, item)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if resp.JsonSchema == nil {
+		w.WriteHeader(resp.Code)
+		return
+	}
+
+	contentType := item.ContentType
+	if contentType == "" {
+		contentType = parser.BodyTypeJSON
+	}
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(resp.Code)
+	_ = json.NewEncoder(w).Encode(sample.FromSchema(resp.JsonSchema))
+}
+
+// selectResponse 根据请求中指定的状态码选择响应
+func selectResponse(r *http.Request, item *parser.ApiItem) (*parser.Response, error) {
+	status := r.Header.Get(HeaderStatus)
+	if status == "" {
+		status = r.URL.Query().Get(QueryStatus)
+	}
+	if status != "" {
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return nil, fmt.Errorf("无效的响应状态码 %s", status)
+		}
+		codes := make([]string, 0, len(item.Responses))
+		for _, resp := range item.Responses {
+			if resp.Code == code {
+				return resp, nil
+			}
+			codes = append(codes, strconv.Itoa(resp.Code))
+		}
+		return nil, fmt.Errorf("接口没有定义 %d 响应，可选的状态码: %s", code, strings.Join(codes, ", "))
+	}
+
+	var selected *parser.Response
+	for _, resp := range item.Responses {
+		if resp.Code >= 200 && resp.Code < 300 && (selected == nil || resp.Code < selected.Code) {
+			selected = resp
+		}
+	}
+	if selected == nil && len(item.Responses) > 0 {
+		selected = item.Responses[0]
+	}
+	return selected, nil
+}
+
+// missingParams 检查请求中缺少的必填参数，返回 参数类型.参数名 列表
+func missingParams(r *http.Request, item *parser.ApiItem, pathParams map[string]string) []string {
+	missing := make([]string, 0)
+	check := func(paramType string, params []parser.Parameter, has func(name string) bool) {
+		for _, param := range params {
+			if param.Required && !has(param.Name) {
+				missing = append(missing, paramType+"."+param.Name)
+			}
+		}
+	}
+
+	check(parser.ParamTypePath, item.Parameters.Path, func(name string) bool {
+		return pathParams[name] != ""
+	})
+	query := r.URL.Query()
+	check(parser.ParamTypeQuery, item.Parameters.Query, func(name string) bool {
+		_, ok := query[name]
+		return ok
+	})
+	check(parser.ParamTypeHeader, item.Parameters.Header, func(name string) bool {
+		return r.Header.Get(name) != ""
+	})
+	check(parser.ParamTypeCookie, item.Parameters.Cookie, func(name string) bool {
+		_, err := r.Cookie(name)
+		return err == nil
+	})
+	if len(item.Parameters.FormData) > 0 {
+		_ = r.ParseMultipartForm(32 << 20)
+		check(parser.ParamTypeForm, item.Parameters.FormData, func(name string) bool {
+			if _, ok := r.Form[name]; ok {
+				return true
+			}
+			if r.MultipartForm != nil {
+				_, ok := r.MultipartForm.File[name]
+				return ok
+			}
+			return false
+		})
+	}
+	if schema := item.Parameters.JsonSchema; schema != nil && len(schema.Required) > 0 && isJSON(r) {
+		body := make(map[string]interface{})
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, name := range schema.Required {
+			if _, ok := body[name]; !ok {
+				missing = append(missing, parser.ParamTypeBody+"."+name)
+			}
+		}
+	}
+	return missing
+}
+
+func isJSON(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "" || mediaType == parser.BodyTypeJSON
+}
+
+func writeError(w http.ResponseWriter, code int, msg string, missing []string) {
+	w.Header().Set("Content-Type", parser.BodyTypeJSON+"; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error   string   `json:"error"`
+		Missing []string `json:"missing,omitempty"`
+	}{msg, missing})
+}
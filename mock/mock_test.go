@@ -0,0 +1,82 @@
+package mock
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	Convey("测试模拟服务", t, func() {
+		petSchema := &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Required:   []string{"name"},
+			Properties: map[string]spec.Schema{"id": *spec.Int64Property(), "name": *spec.StringProperty()},
+		}}
+		parser.SchemaSetPropertiesOrders(petSchema, []string{"name", "id"})
+		named := *spec.StringProperty()
+		named.Example = "Kitty"
+		errSchema := &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"msg": named},
+		}}
+
+		s := New([]parser.ApiItem{
+			{
+				Method: parser.MethodGet,
+				Path:   "/pet/{petId}",
+				Parameters: parser.Parameters{
+					Path:   []parser.Parameter{{Name: "petId", Required: true}},
+					Header: []parser.Parameter{{Name: "Authorization", Required: true}},
+				},
+				Responses: []*parser.Response{
+					{Code: 404, Name: "不存在", JsonSchema: errSchema},
+					{Code: 200, Name: "成功", JsonSchema: petSchema},
+				},
+			},
+			{Method: parser.MethodGet, Path: "/pet/findByStatus"},
+			{Method: parser.MethodPost, Path: "/pet", Parameters: parser.Parameters{JsonSchema: petSchema}},
+		})
+
+		serve := func(req *http.Request) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			return rec
+		}
+
+		// ---- 缺少必填参数 --------------------
+		rec := serve(httptest.NewRequest(http.MethodGet, "/pet/1", nil))
+		So(rec.Code, ShouldEqual, http.StatusBadRequest)
+		So(rec.Body.String(), ShouldContainSubstring, "header.Authorization")
+
+		// ---- 默认返回 2xx 响应 --------------------
+		req := httptest.NewRequest(http.MethodGet, "/pet/1", nil)
+		req.Header.Set("Authorization", "bearer")
+		rec = serve(req)
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Body.String(), ShouldEqual, `{"name":"string","id":1}`+"\n")
+
+		// ---- 指定状态码 --------------------
+		req = httptest.NewRequest(http.MethodGet, "/pet/1?__status=404", nil)
+		req.Header.Set("Authorization", "bearer")
+		rec = serve(req)
+		So(rec.Code, ShouldEqual, http.StatusNotFound)
+		So(rec.Body.String(), ShouldEqual, `{"msg":"Kitty"}`+"\n")
+
+		// ---- 静态路径优先匹配 --------------------
+		rec = serve(httptest.NewRequest(http.MethodGet, "/pet/findByStatus", nil))
+		So(rec.Code, ShouldEqual, http.StatusOK)
+
+		// ---- 请求体缺少必填字段 --------------------
+		rec = serve(httptest.NewRequest(http.MethodPost, "/pet", strings.NewReader(`{"id":1}`)))
+		So(rec.Code, ShouldEqual, http.StatusBadRequest)
+		So(rec.Body.String(), ShouldContainSubstring, "body.name")
+
+		rec = serve(httptest.NewRequest(http.MethodDelete, "/pet/1", nil))
+		So(rec.Code, ShouldEqual, http.StatusNotFound)
+	})
+}
@@ -1,19 +1,21 @@
 package parser
 
 import (
+	"fmt"
 	"github.com/go-openapi/spec"
-	"path"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
 // ApiItem 接口文档
 type ApiItem struct {
-	Title       string `json:"title"`       // 必填，接口名称
-	Folder      string `json:"folder"`      // 接口目录，多级目录用 / 隔开，例如 “一层/二层/三层”
-	Status      string `json:"status"`      // 接口状态
-	Description string `json:"description"` // 接口说明
-	Remark      string `json:"remark"`      // 备注信息
+	Title       string   `json:"title"`          // 必填，接口名称
+	Folder      Folder   `json:"folder"`         // 接口目录，序列化为用 / 隔开的多级目录，例如 “一层/二层/三层”
+	Tags        []string `json:"tags,omitempty"` // 接口标签
+	Status      Status   `json:"status"`         // 接口状态
+	Description string   `json:"description"`    // 接口说明
+	Remark      string   `json:"remark"`         // 备注信息
 
 	Method string `json:"method"` // 必填，http 请求方式
 	Path   string `json:"path"`   // 必填，http 请求路径
@@ -21,11 +23,54 @@ type ApiItem struct {
 	Parameters  Parameters  `json:"parameters,omitempty"` // 请求参数
 	ContentType string      `json:"content_type"`         // 响应类型
 	Responses   []*Response `json:"responses,omitempty"`  // 返回响应
+
+	Depends []string `json:"depends,omitempty"` // 依赖的接口，格式为：[METHOD] [url]
+
+	PkgId  string  `json:"pkg_id,omitempty"` // 接口注释所在的 Go 包
+	Source *Source `json:"source,omitempty"` // 接口注释所在的代码位置
+}
+
+// Source 接口注释所在的代码位置
+type Source struct {
+	File string `json:"file"` // 文件路径
+	Line int    `json:"line"` // 方法所在行号
+	Func string `json:"func"` // 方法名称
 }
 
-// Name 文档分类+标题
+func (s *Source) String() string {
+	if s == nil || s.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d %s()", s.File, s.Line, s.Func)
+}
+
+// Name 文档分类+标题，目录和标题中的 \ 和 / 会被转义
 func (p *ApiItem) Name() string {
-	return path.Join(p.Folder, p.Title)
+	return p.Folder.Join(p.Title).String()
+}
+
+// Endpoint 接口标识，如：GET /pet/{petId}
+func (p *ApiItem) Endpoint() string {
+	return strings.ToUpper(p.Method) + " " + p.Path
+}
+
+// pathParamRegexp 匹配路径参数，如：/pet/{petId}
+var pathParamRegexp = regexp.MustCompile(`{([^{}/]+)}`)
+
+// PathParams 按出现顺序获取路径中的参数名，如：/pet/{petId} 中的 petId
+func PathParams(path string) []string {
+	names := make([]string, 0)
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// ReplacePathParams 使用 replace 的返回值替换路径中的参数，如：/pet/{petId} 替换为 /pet/:petId
+func ReplacePathParams(path string, replace func(name string) string) string {
+	return pathParamRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		return replace(s[1 : len(s)-1])
+	})
 }
 
 // Invalid 没有标题或Url，不是有效的API文档
@@ -36,6 +81,7 @@ func (p *ApiItem) Invalid() bool {
 func (p *ApiItem) UseCommon(comm *ApiItem) {
 	p.AddFolder(comm.Folder)
 	p.AddRemark(comm.Remark)
+	p.AddTags(comm.Tags...)
 	for _, header := range comm.Parameters.Header {
 		p.Parameters.Header = append(p.Parameters.Header, header)
 	}
@@ -68,8 +114,25 @@ func (p *ApiItem) UseCommon(comm *ApiItem) {
 	}
 }
 
-func (p *ApiItem) AddFolder(folder string) {
-	p.Folder = path.Join(folder, p.Folder)
+// AddFolder 添加上级目录
+func (p *ApiItem) AddFolder(folder Folder) {
+	p.Folder = folder.Join(p.Folder...)
+}
+
+// AddTags 添加标签，忽略重复的标签
+func (p *ApiItem) AddTags(tags ...string) {
+	for _, tag := range tags {
+		exists := false
+		for _, t := range p.Tags {
+			if t == tag {
+				exists = true
+				break
+			}
+		}
+		if !exists && tag != "" {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
 }
 
 func (p *ApiItem) AddRemark(remark string) {
@@ -0,0 +1,16 @@
+package parser
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestPathParams(t *testing.T) {
+	Convey("测试获取和替换路径参数", t, func() {
+		So(PathParams("/pet"), ShouldBeEmpty)
+		So(PathParams("/store/{storeId}/pet/{petId}"), ShouldResemble, []string{"storeId", "petId"})
+		So(ReplacePathParams("/store/{storeId}/pet/{petId}", func(name string) string {
+			return ":" + name
+		}), ShouldEqual, "/store/:storeId/pet/:petId")
+	})
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Folder 接口目录，每一项为一级目录的名称。
+//
+//	在注释和 Apifox 中，多级目录使用 / 分隔，其中 \ 和 / 为特殊字符，需要转义：\/ 表示字符 /，\\ 表示字符 \。
+//	例如：Orders/Refunds v1\/v2 表示两级目录 “Orders” 和 “Refunds v1/v2”。
+type Folder []string
+
+// ParseFolder 解析转义后的目录字符串，忽略开头和结尾的 /，每级目录名称会去掉首尾空格。
+func ParseFolder(s string) (Folder, error) {
+	s = strings.TrimSpace(s)
+	folder := make(Folder, 0)
+	if s == "" {
+		return folder, nil
+	}
+
+	var segment strings.Builder
+	addSegment := func(last bool) error {
+		name := strings.TrimSpace(segment.String())
+		segment.Reset()
+		if name == "" {
+			// 开头和结尾的 / 不作为空目录
+			if last || len(folder) == 0 {
+				return nil
+			}
+			return fmt.Errorf("目录 %q 中有空的目录名称", s)
+		}
+		folder = append(folder, name)
+		return nil
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			if i+1 >= len(runes) || (runes[i+1] != '\\' && runes[i+1] != '/') {
+				return nil, fmt.Errorf("目录 %q 中的转义字符错误，\\/ 表示字符 /，\\\\ 表示字符 \\", s)
+			}
+			i++
+			segment.WriteRune(runes[i])
+		case '/':
+			if err := addSegment(false); err != nil {
+				return nil, err
+			}
+		default:
+			segment.WriteRune(r)
+		}
+	}
+	if err := addSegment(true); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// EscapeFolderName 转义目录名称中的 \ 和 /
+func EscapeFolderName(name string) string {
+	return strings.NewReplacer(`\`, `\\`, `/`, `\/`).Replace(name)
+}
+
+// String 转义每级目录名称，并用 / 连接
+func (f Folder) String() string {
+	names := make([]string, len(f))
+	for i, name := range f {
+		names[i] = EscapeFolderName(name)
+	}
+	return strings.Join(names, "/")
+}
+
+// Join 在当前目录后添加子目录，返回新的目录
+func (f Folder) Join(sub ...string) Folder {
+	folder := make(Folder, 0, len(f)+len(sub))
+	folder = append(folder, f...)
+	return append(folder, sub...)
+}
+
+// FilePath 按目录层级生成的文件路径，每级目录名称中文件名不能使用的字符替换为 _，如：宠物商城/宠物管理.md
+func (f Folder) FilePath(ext string) string {
+	names := make([]string, 0, len(f))
+	for _, name := range f {
+		names = append(names, fileName(name))
+	}
+	return path.Join(names...) + ext
+}
+
+// fileName 替换文件名中不能使用的字符
+func fileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// MarshalJSON 使用转义后的字符串格式
+func (f Folder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON 解析转义后的字符串格式
+func (f *Folder) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	folder, err := ParseFolder(s)
+	if err != nil {
+		return err
+	}
+	*f = folder
+	return nil
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestParseFolder(t *testing.T) {
+	Convey("测试解析目录", t, func() {
+		for s, want := range map[string]Folder{
+			"":                          {},
+			"一级/二级/三级":                  {"一级", "二级", "三级"},
+			"/一级/二级/":                   {"一级", "二级"},
+			` Orders / Refunds v1\/v2 `: {"Orders", "Refunds v1/v2"},
+			`a\\b/..`:                   {`a\b`, ".."},
+		} {
+			folder, err := ParseFolder(s)
+			So(err, ShouldBeNil)
+			So(folder, ShouldResemble, want)
+		}
+
+		for _, s := range []string{"a//b", `a\b`, `a\`} {
+			_, err := ParseFolder(s)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("测试目录转义和序列化", t, func() {
+		folder := Folder{"Orders", "Refunds v1/v2", `a\b`}
+		So(folder.String(), ShouldEqual, `Orders/Refunds v1\/v2/a\\b`)
+
+		data, err := json.Marshal(folder)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `"Orders/Refunds v1\\/v2/a\\\\b"`)
+
+		var got Folder
+		So(json.Unmarshal(data, &got), ShouldBeNil)
+		So(got, ShouldResemble, folder)
+	})
+
+	Convey("测试按目录层级生成文件路径", t, func() {
+		So(Folder{"宠物商城", "宠物管理"}.FilePath(".md"), ShouldEqual, "宠物商城/宠物管理.md")
+		So(Folder{"Refunds v1/v2", "..", "a:b"}.FilePath(".http"), ShouldEqual, "Refunds v1_v2/_/a_b.http")
+	})
+
+	Convey("测试添加上级目录", t, func() {
+		p := NewParser()
+		apiItem := &ApiItem{Title: "退款"}
+		So(p.parseGoComment(apiItem, nil, "", `// @folder Refunds v1\/v2`), ShouldBeNil)
+		apiItem.UseCommon(&ApiItem{Folder: Folder{"Orders"}})
+		So(apiItem.Folder, ShouldResemble, Folder{"Orders", "Refunds v1/v2"})
+		So(apiItem.Name(), ShouldEqual, `Orders/Refunds v1\/v2/退款`)
+
+		So(p.parseGoComment(apiItem, nil, "", `// @folder a\b`), ShouldNotBeNil)
+	})
+}
@@ -7,8 +7,11 @@ import (
 	"github.com/whaios/apigo/log"
 	"go/ast"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 // 参数类型
@@ -43,11 +46,13 @@ const (
 
 // API 接口注释支持的标签
 const (
-	TagTitle  = "@title"  // 接口名称，默认取方法名称
-	TagFolder = "@folder" // 接口目录，多级目录用 / 隔开
-	TagStatus = "@status" // 可选，接口状态
-	TagDesc   = "@desc"   // 可选，接口说明
-	TagRemark = "@remark" // 可选，备注信息
+	TagTitle   = "@title"   // 接口名称，默认取方法名称
+	TagFolder  = "@folder"  // 接口目录，多级目录用 / 隔开，\/ 表示字符 /，\\ 表示字符 \
+	TagStatus  = "@status"  // 可选，接口状态
+	TagDesc    = "@desc"    // 可选，接口说明
+	TagRemark  = "@remark"  // 可选，备注信息
+	TagTag     = "@tag"     // 可选，接口标签，多个标签用空格或逗号隔开
+	TagDepends = "@depends" // 可选，依赖的接口，格式为：[method] [url]，用于检查接口状态的生命周期规则
 
 	TagUrl         = "@url"         // 接口URL，格式为：[method] [url]
 	TagBodyType    = "@bodytype"    // 可选，Body 类型，仅影响具有请求正文的操作，例如 POST、PUT 和 PATCH。
@@ -65,6 +70,7 @@ func NewParser() *Parser {
 }
 
 type Parser struct {
+	schemasMu     sync.RWMutex
 	parsedSchemas map[*goscanner.AstTypeSpec]*spec.Schema
 	scanner       *goscanner.Scanner
 }
@@ -73,6 +79,10 @@ func (p *Parser) SetScanner(scanner *goscanner.Scanner) {
 	p.scanner = scanner
 }
 
+func (p *Parser) Scanner() *goscanner.Scanner {
+	return p.scanner
+}
+
 // Scan 扫描指定目录中的 go 代码，返回文件个数。
 func (p *Parser) Scan(dir string) (int, error) {
 	// 扫描 go 代码
@@ -80,26 +90,106 @@ func (p *Parser) Scan(dir string) (int, error) {
 	return p.scanner.FileCount(), err
 }
 
+// Update 重新解析发生变化的 go 代码文件。
+//
+//	某个文件解析出错时继续解析其他文件，出错的文件保留上次解析的结果，返回所有文件的错误。
+//	类型的解析结果会嵌套引用其他类型，所以清空所有已解析的类型，在下次 Parse 时重新解析。
+func (p *Parser) Update(paths ...string) error {
+	errs := make([]string, 0)
+	for _, path := range paths {
+		if err := p.scanner.UpdateFile(path); err != nil {
+			errs = append(errs, fmt.Sprintf("解析文件 %s 出错: %s", path, err))
+		}
+	}
+	p.schemasMu.Lock()
+	p.parsedSchemas = make(map[*goscanner.AstTypeSpec]*spec.Schema)
+	p.schemasMu.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
 // Parse 解析 go 代码注释为 API接口文档
+//
+//	先批量加载注释中引用的外部包，再使用多个协程并发解析每个 go 代码文件，接口文档按文件顺序排列。
 func (p *Parser) Parse() ([]ApiItem, error) {
+	files := p.scanner.Files()
+	if err := p.scanner.LoadPackages(importedPkgs(files)...); err != nil {
+		return nil, err
+	}
+
+	results := make([][]ApiItem, len(files))
+	errs := make([]error, len(files))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, file := range files {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, file *goscanner.AstFile) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			log.Debug(log.UpdateSpinner("解析文件 %s", file.Path()))
+			results[i], errs[i] = p.parseGoFile(file)
+		}(i, file)
+	}
+	wg.Wait()
+
 	apiItems := make([]ApiItem, 0)
-	// 循环解析每个 go 代码文件中的注释
-	for _, file := range p.scanner.Files() {
-		log.Debug(log.UpdateSpinner("解析文件 %s", file.Path()))
-		if items, err := p.parseGoFile(file); err != nil {
-			return apiItems, err
-		} else {
-			apiItems = append(apiItems, items...)
+	for i := range files {
+		if errs[i] != nil {
+			return apiItems, errs[i]
+		}
+		for _, item := range results[i] {
+			apiItems = append(apiItems, item)
+			log.Info("生成接口文档(%d) %s", len(apiItems), item.Name())
 		}
 	}
 	return apiItems, nil
 }
 
+// 注释中引用的其他包中的类型，如：model.Pet
+var pkgTypePattern = regexp.MustCompile(`([A-Za-z_]\w*)\.[A-Za-z_]\w*`)
+
+// importedPkgs 获取 @param、@success、@resp 注释中引用的包，以及 . 导入的包
+func importedPkgs(files []*goscanner.AstFile) []string {
+	pkgs := make([]string, 0)
+	exists := make(map[string]bool)
+	add := func(pkg string) {
+		if pkg != "" && !exists[pkg] {
+			exists[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	for _, file := range files {
+		for _, pkg := range file.DotImports() {
+			add(pkg)
+		}
+		for _, group := range file.File().Comments {
+			for _, comment := range group.List {
+				fields := strings.Fields(strings.TrimLeft(comment.Text, "/"))
+				if len(fields) < 2 {
+					continue
+				}
+				switch strings.ToLower(fields[0]) {
+				case TagParam, TagSuccess, TagResp:
+					for _, m := range pkgTypePattern.FindAllStringSubmatch(strings.Join(fields[1:], " "), -1) {
+						add(file.GetImportPkg(m[1]))
+					}
+				}
+			}
+		}
+	}
+	return pkgs
+}
+
 // parseGoFile 解析 go 代码文件中的注释
 func (p *Parser) parseGoFile(file *goscanner.AstFile) ([]ApiItem, error) {
 	commItem := &ApiItem{}
 	apiItems := make([]ApiItem, 0)
-	order := 1
 	for _, astDescription := range file.File().Decls {
 		switch astDescription.(type) {
 		case *ast.GenDecl:
@@ -118,7 +208,12 @@ func (p *Parser) parseGoFile(file *goscanner.AstFile) ([]ApiItem, error) {
 			astDecl := astDescription.(*ast.FuncDecl)
 			if astDecl.Doc != nil && astDecl.Doc.List != nil {
 				log.Debug("解析方法注释: %s %s()", file.Path(), astDecl.Name.Name)
-				apiItem := &ApiItem{}
+				apiItem := &ApiItem{PkgId: file.PkgId()}
+				apiItem.Source = &Source{
+					File: file.Path(),
+					Line: file.Position(astDecl.Pos()).Line,
+					Func: astDecl.Name.Name,
+				}
 				// 逐行解析方法上的注释块
 				for _, comment := range astDecl.Doc.List {
 					log.Debug("	> 注释: %s", comment.Text)
@@ -133,9 +228,7 @@ func (p *Parser) parseGoFile(file *goscanner.AstFile) ([]ApiItem, error) {
 				}
 
 				apiItem.UseCommon(commItem)
-				log.Info("生成接口文档(%d) %s", order, apiItem.Name())
 				apiItems = append(apiItems, *apiItem)
-				order++
 			}
 		}
 	}
@@ -160,9 +253,14 @@ func (p *Parser) parseGoComment(apiItem *ApiItem, file *goscanner.AstFile, funcN
 	case funcName, TagTitle:
 		apiItem.Title = lineRemainder
 	case TagFolder:
-		apiItem.AddFolder(lineRemainder)
+		var folder Folder
+		if folder, err = ParseFolder(lineRemainder); err == nil {
+			apiItem.AddFolder(folder)
+		}
 	case TagStatus:
-		apiItem.Status = lineRemainder
+		apiItem.Status, err = ParseStatus(lineRemainder)
+	case TagDepends:
+		err = p.parseDependsComment(apiItem, lineRemainder)
 	case TagUrl:
 		err = p.parseUrlComment(apiItem, lineRemainder)
 	case TagDesc:
@@ -182,6 +280,10 @@ func (p *Parser) parseGoComment(apiItem *ApiItem, file *goscanner.AstFile, funcN
 		err = p.parseRespComment(apiItem, lineRemainder, file)
 	case TagRemark:
 		apiItem.AddRemark(lineRemainder)
+	case TagTag:
+		apiItem.AddTags(strings.FieldsFunc(lineRemainder, func(r rune) bool {
+			return r == ',' || r == '，' || unicode.IsSpace(r)
+		})...)
 	}
 
 	return err
@@ -234,6 +336,16 @@ func (p *Parser) parseBodyTypeComment(apiItem *ApiItem, comment string) error {
 	return nil
 }
 
+// parseDependsComment 解析依赖的接口，格式为：[method] [url]
+func (p *Parser) parseDependsComment(apiItem *ApiItem, comment string) error {
+	fields := strings.Fields(comment)
+	if len(fields) != 2 {
+		return fmt.Errorf("无法解析 depends 注释 \"%s\"", comment)
+	}
+	apiItem.Depends = append(apiItem.Depends, strings.ToUpper(fields[0])+" "+fields[1])
+	return nil
+}
+
 var paramPattern = regexp.MustCompile(`(\w+)\s+(\S+)\s+([\w\-.\\{}=,\[\s\]]+)\s+(\w+)\s+"([^"]*)"\s+"([^"]*)"`)
 
 // parseParamComment 解析参数
@@ -414,7 +526,7 @@ func (p *Parser) ParseType(typeName string, astFile *goscanner.AstFile) (*spec.S
 	log.Debug("解析类型: %s", typeName)
 
 	if isGolangPrimitiveType(typeName) {
-		return primitiveSchema(transToValidSchemeType(typeName)), nil
+		return primitiveSchema(TransToValidSchemeType(typeName)), nil
 	}
 
 	typeSpecDef, err := p.scanner.GetType(typeName, astFile)
@@ -425,19 +537,71 @@ func (p *Parser) ParseType(typeName string, astFile *goscanner.AstFile) (*spec.S
 		return nil, fmt.Errorf("没有找到类型定义: %s", typeName)
 	}
 
+	p.schemasMu.RLock()
 	schema, found := p.parsedSchemas[typeSpecDef]
+	p.schemasMu.RUnlock()
 	if found {
-		return schema, nil
+		return copySchema(schema), nil
 	}
 
+	// 多个协程同时解析同一类型时，结果相同，保留任意一个即可
 	schema, err = p.parseTypeExpr(typeSpecDef.Name(), typeSpecDef.File, typeSpecDef.TypeSpec.Type, false)
 	if err != nil {
 		return nil, err
 	}
 	SchemaSetTypeFullName(schema, typeSpecDef.Id())
+	if typeSpecDef.File.File().Name.Name == "main" {
+		SchemaSetMainPackage(schema)
+	}
 
+	p.schemasMu.Lock()
 	p.parsedSchemas[typeSpecDef] = schema
-	return schema, nil
+	p.schemasMu.Unlock()
+	return copySchema(schema), nil
+}
+
+// copySchema 深度复制已解析的类型，调用方设置字段说明、组装字段、修改属性等不会影响缓存的类型
+func copySchema(schema *spec.Schema) *spec.Schema {
+	if schema == nil {
+		return nil
+	}
+	cp := *schema
+	if schema.ExtraProps != nil {
+		cp.ExtraProps = make(map[string]interface{}, len(schema.ExtraProps))
+		for k, v := range schema.ExtraProps {
+			if orders, ok := v.([]string); ok {
+				v = append([]string{}, orders...)
+			}
+			cp.ExtraProps[k] = v
+		}
+	}
+	if schema.Properties != nil {
+		cp.Properties = make(spec.SchemaProperties, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			cp.Properties[name] = *copySchema(&prop)
+		}
+	}
+	if schema.AllOf != nil {
+		cp.AllOf = make([]spec.Schema, len(schema.AllOf))
+		for i := range schema.AllOf {
+			cp.AllOf[i] = *copySchema(&schema.AllOf[i])
+		}
+	}
+	if schema.Items != nil {
+		cp.Items = &spec.SchemaOrArray{Schema: copySchema(schema.Items.Schema)}
+		for i := range schema.Items.Schemas {
+			cp.Items.Schemas = append(cp.Items.Schemas, *copySchema(&schema.Items.Schemas[i]))
+		}
+	}
+	if schema.AdditionalProperties != nil {
+		cp.AdditionalProperties = &spec.SchemaOrBool{
+			Allows: schema.AdditionalProperties.Allows,
+			Schema: copySchema(schema.AdditionalProperties.Schema),
+		}
+	}
+	cp.Required = append([]string(nil), schema.Required...)
+	cp.Enum = append([]interface{}(nil), schema.Enum...)
+	return &cp
 }
 
 func (p *Parser) parseTypeExpr(rootTypeName string, file *goscanner.AstFile, typeExpr ast.Expr, ref bool) (*spec.Schema, error) {
@@ -537,7 +701,7 @@ func (p *Parser) parseStruct(rootTypeName string, file *goscanner.AstFile, st *a
 		case dataType == "" || dataType == INTERFACE:
 			fschema = primitiveSchema(OBJECT)
 		case isGolangPrimitiveType(dataType):
-			fschema = primitiveSchema(transToValidSchemeType(dataType))
+			fschema = primitiveSchema(TransToValidSchemeType(dataType))
 		case dataType == TIME:
 			fschema = primitiveSchema(STRING)
 			fschema.Format = "date-time"
@@ -700,7 +864,8 @@ const (
 	IgnoreNameOverridePrefix = '$'
 )
 
-func transToValidSchemeType(typeName string) string {
+// TransToValidSchemeType 将 Go 基础类型转换为 OpenAPI 的数据类型，如：int64 转换为 integer，其他类型原样返回
+func TransToValidSchemeType(typeName string) string {
 	switch typeName {
 	case "uint", "int", "uint8", "int8", "uint16", "int16", "byte":
 		return INTEGER
@@ -743,6 +908,7 @@ func parseFields(s string) []string {
 const (
 	schemaExtraTypeFullName     = "apigo-type-full-name"
 	schemaExtraComposedFieldKey = "apigo-composed-field-key"
+	schemaExtraMainPackage      = "apigo-main-package"
 )
 
 var schemaExtraPropertiesOrders = "apigo-properties-orders"
@@ -759,6 +925,12 @@ func SchemaSetTypeFullName(schema *spec.Schema, val string) {
 	schema.ExtraProps[schemaExtraTypeFullName] = val
 }
 
+// SchemaWithTypeFullName 设置类型全名后返回 schema，用于构造命名类型
+func SchemaWithTypeFullName(schema *spec.Schema, val string) *spec.Schema {
+	SchemaSetTypeFullName(schema, val)
+	return schema
+}
+
 func SchemaGetTypeFullName(schema *spec.Schema) string {
 	if schema.ExtraProps != nil {
 		if val, ok := schema.ExtraProps[schemaExtraTypeFullName]; ok {
@@ -768,6 +940,24 @@ func SchemaGetTypeFullName(schema *spec.Schema) string {
 	return ""
 }
 
+// SchemaSetMainPackage 标记类型定义在 main 包中，其他包不能导入该类型
+func SchemaSetMainPackage(schema *spec.Schema) {
+	if schema.ExtraProps == nil {
+		schema.ExtraProps = make(map[string]interface{})
+	}
+	schema.ExtraProps[schemaExtraMainPackage] = true
+}
+
+// SchemaIsMainPackage 类型是否定义在 main 包中
+func SchemaIsMainPackage(schema *spec.Schema) bool {
+	if schema.ExtraProps != nil {
+		if val, ok := schema.ExtraProps[schemaExtraMainPackage].(bool); ok {
+			return val
+		}
+	}
+	return false
+}
+
 func SchemaSetComposedFieldKey(schema *spec.Schema, val string) {
 	if schema.ExtraProps == nil {
 		schema.ExtraProps = make(map[string]interface{})
@@ -799,3 +989,48 @@ func SchemaGetPropertiesOrders(schema *spec.Schema) []string {
 	}
 	return make([]string, 0)
 }
+
+// SchemaMaxDepth 展开数据结构的最大层数，避免递归类型导致死循环
+const SchemaMaxDepth = 10
+
+// SchemaType 数据类型，没有指定类型但有属性、allOf 或 additionalProperties 时为 object
+func SchemaType(schema *spec.Schema) string {
+	if len(schema.Type) > 0 {
+		return schema.Type[0]
+	}
+	if len(schema.Properties) > 0 || len(schema.AllOf) > 0 || schema.AdditionalProperties != nil {
+		return OBJECT
+	}
+	return ""
+}
+
+// SchemaPropertyNames 按排序获取属性名（包括 allOf 中的属性），allOf 中的属性排在前面，没有排序的属性按字母顺序排在最后
+func SchemaPropertyNames(schema *spec.Schema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	exists := make(map[string]bool)
+	props := make(map[string]bool)
+	var addOrders func(s *spec.Schema)
+	addOrders = func(s *spec.Schema) {
+		for i := range s.AllOf {
+			addOrders(&s.AllOf[i])
+		}
+		for name := range s.Properties {
+			props[name] = true
+		}
+		for _, name := range SchemaGetPropertiesOrders(s) {
+			if _, ok := s.Properties[name]; ok && !exists[name] {
+				exists[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	addOrders(schema)
+	others := make([]string, 0)
+	for name := range props {
+		if !exists[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
@@ -2,8 +2,11 @@ package parser
 
 import (
 	"encoding/json"
+	"github.com/go-openapi/spec"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/whaios/apigo/goscanner"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -12,6 +15,10 @@ func TestParseGoComment(t *testing.T) {
 		wantApiItem := `{
     "title": "示例接口",
     "folder": "一级目录/二级目录",
+    "tags": [
+        "pet",
+        "internal"
+    ],
     "status": "released",
     "description": "接口说明",
     "remark": "接口备注",
@@ -65,6 +72,7 @@ func TestParseGoComment(t *testing.T) {
 			`// `,
 			`// @folder	一级目录/二级目录`,
 			`// @status 	released`,
+			`// @tag		pet, internal pet`,
 			`// @desc		接口说明`,
 			`// @remark		接口备注`,
 			`// @url		GET /pet/{petId}`,
@@ -88,6 +96,133 @@ func TestParseGoComment(t *testing.T) {
 	})
 }
 
+func TestParser_Update(t *testing.T) {
+	Convey("测试文件变化后重新解析，部分文件出错", t, func() {
+		dir := t.TempDir()
+		write := func(name, content string) string {
+			path := filepath.Join(dir, name)
+			So(os.WriteFile(path, []byte(content), 0644), ShouldBeNil)
+			return path
+		}
+		write("go.mod", "module shop\n\ngo 1.19\n")
+		aFile := write("a.go", "package shop\n\n// GetA 查询A\n// @url GET /a\nfunc GetA() {}\n")
+		bFile := write("b.go", "package shop\n\n// GetB 查询B\n// @url GET /b\nfunc GetB() {}\n")
+
+		p := NewParser()
+		_, err := p.Scan(dir)
+		So(err, ShouldBeNil)
+
+		// a.go 编辑中出现语法错误，b.go 正常修改
+		write("a.go", "package shop\n\nfunc GetA( {}\n")
+		write("b.go", "package shop\n\n// GetB 查询B\n// @url GET /b2\nfunc GetB() {}\n")
+		err = p.Update(aFile, bFile)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "a.go")
+
+		items, err := p.Parse()
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 2)
+		So(items[0].Path, ShouldEqual, "/a")
+		So(items[1].Path, ShouldEqual, "/b2")
+	})
+}
+
+func TestParser_ParseDeterministic(t *testing.T) {
+	Convey("测试多次解析的结果相同，共用的类型不会互相影响", t, func() {
+		dir := t.TempDir()
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module shop\n\ngo 1.19\n"), 0644), ShouldBeNil)
+		src := `package shop
+
+type Tag struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Pet struct {
+	Tag Tag ` + "`json:\"tag\"`" + ` // 宠物标签
+}
+
+type Order struct {
+	Tag Tag ` + "`json:\"tag\"`" + ` // 订单标签
+}
+
+type Resp struct {
+	Data interface{} ` + "`json:\"data\"`" + `
+}
+`
+		for _, name := range []string{"a", "b", "c", "d"} {
+			src += "\n// Get" + name + " 查询" + name + "\n// @url GET /" + name +
+				"\n// @success Resp{data=Pet}\n// @resp 400 \"失败\" Resp{data=Order}\nfunc Get" + name + "() {}\n"
+		}
+		src += "\n// GetTag 查询标签\n// @url GET /tag\n// @success Tag{}\n// @resp 400 \"失败\" Resp{data}\nfunc GetTag() {}\n"
+		src += "\n// GetResp 查询响应\n// @url GET /resp\n// @success Resp{}\nfunc GetResp() {}\n"
+		So(os.WriteFile(filepath.Join(dir, "shop.go"), []byte(src), 0644), ShouldBeNil)
+
+		parse := func() string {
+			p := NewParser()
+			_, err := p.Scan(dir)
+			So(err, ShouldBeNil)
+			items, err := p.Parse()
+			So(err, ShouldBeNil)
+			So(items, ShouldHaveLength, 6)
+			So(items[0].Path, ShouldEqual, "/a")
+			So(items[3].Path, ShouldEqual, "/d")
+
+			// 组装的响应类型为 allOf: [Resp, {data: Pet}]
+			pet := items[0].Responses[0].JsonSchema.AllOf[1].Properties["data"]
+			So(pet.Properties["tag"].Description, ShouldEqual, "宠物标签")
+			order := items[0].Responses[1].JsonSchema.AllOf[1].Properties["data"]
+			So(order.Properties["tag"].Description, ShouldEqual, "订单标签")
+
+			// 字段说明和组装字段不能修改缓存的类型
+			So(items[4].Responses[0].JsonSchema.Description, ShouldBeEmpty)
+			So(SchemaGetComposedFieldKey(items[4].Responses[1].JsonSchema), ShouldEqual, "data")
+			So(SchemaGetComposedFieldKey(items[5].Responses[0].JsonSchema), ShouldBeEmpty)
+
+			data, err := json.Marshal(items)
+			So(err, ShouldBeNil)
+			return string(data)
+		}
+		first := parse()
+		for i := 0; i < 5; i++ {
+			So(parse(), ShouldEqual, first)
+		}
+
+		// 修改返回的类型中嵌套的属性，不会影响缓存的类型
+		p := NewParser()
+		_, err := p.Scan(dir)
+		So(err, ShouldBeNil)
+		file := p.Scanner().GetFile("shop.go")
+		pet, err := p.ParseType("Pet", file)
+		So(err, ShouldBeNil)
+		tag := pet.Properties["tag"]
+		tag.Properties["name"] = *spec.Int64Property()
+		SchemaGetPropertiesOrders(&tag)[0] = "changed"
+		pet, err = p.ParseType("Pet", file)
+		So(err, ShouldBeNil)
+		cached := pet.Properties["tag"]
+		So(cached.Properties["name"].Type, ShouldResemble, spec.StringOrArray{"string"})
+		So(SchemaGetPropertiesOrders(&cached), ShouldResemble, []string{"name"})
+	})
+}
+
+func TestSchemaPropertyNames(t *testing.T) {
+	Convey("测试按排序获取属性名", t, func() {
+		base := &spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{
+			"code": *spec.Int32Property(), "msg": *spec.StringProperty(),
+		}}}
+		SchemaSetPropertiesOrders(base, []string{"msg", "code"})
+		schema := spec.ComposedSchema(*base, spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{
+			"data": *spec.StringProperty(), "alias": *spec.StringProperty(),
+		}}})
+		schema.Properties = map[string]spec.Schema{"extra": *spec.StringProperty()}
+
+		So(SchemaPropertyNames(base), ShouldResemble, []string{"msg", "code"})
+		// allOf 中的属性排在前面，没有排序的属性按字母顺序排在最后
+		So(SchemaPropertyNames(schema), ShouldResemble, []string{"msg", "code", "alias", "data", "extra"})
+		So(SchemaType(schema), ShouldEqual, OBJECT)
+	})
+}
+
 func Test_ParseSchema(t *testing.T) {
 	Convey("测试解析结构体", t, func() {
 		scanner := goscanner.New()
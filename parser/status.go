@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status 接口状态，与 Apifox 中的接口状态一致
+type Status string
+
+// Apifox 接口状态，按接口的生命周期排序
+const (
+	StatusDesigning   Status = "designing"   // 设计中
+	StatusPending     Status = "pending"     // 待确定
+	StatusDeveloping  Status = "developing"  // 开发中
+	StatusIntegrating Status = "integrating" // 联调中
+	StatusTesting     Status = "testing"     // 测试中
+	StatusTested      Status = "tested"      // 已测完
+	StatusReleased    Status = "released"    // 已发布
+	StatusDeprecated  Status = "deprecated"  // 将废弃
+	StatusException   Status = "exception"   // 有异常
+	StatusObsolete    Status = "obsolete"    // 已废弃
+)
+
+// Statuses 所有的接口状态
+var Statuses = []Status{
+	StatusDesigning, StatusPending, StatusDeveloping, StatusIntegrating, StatusTesting,
+	StatusTested, StatusReleased, StatusDeprecated, StatusException, StatusObsolete,
+}
+
+// statusAliases 接口状态的别名，包括 Apifox 中显示的中文名称
+var statusAliases = map[string]Status{
+	"设计中": StatusDesigning,
+	"待确定": StatusPending,
+	"开发中": StatusDeveloping,
+	"联调中": StatusIntegrating,
+	"测试中": StatusTesting,
+	"已测完": StatusTested,
+	"已发布": StatusReleased,
+	"将废弃": StatusDeprecated,
+	"有异常": StatusException,
+	"已废弃": StatusObsolete,
+
+	"design":  StatusDesigning,
+	"dev":     StatusDeveloping,
+	"develop": StatusDeveloping,
+	"test":    StatusTesting,
+	"release": StatusReleased,
+}
+
+// ParseStatus 解析接口状态，支持状态代码（不区分大小写）和别名（如：已发布），空字符串表示不指定状态。
+func ParseStatus(s string) (Status, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	for _, status := range Statuses {
+		if strings.EqualFold(s, string(status)) {
+			return status, nil
+		}
+	}
+	if status, ok := statusAliases[strings.ToLower(s)]; ok {
+		return status, nil
+	}
+
+	valid := make([]string, 0, len(Statuses))
+	for _, status := range Statuses {
+		valid = append(valid, string(status))
+	}
+	return "", fmt.Errorf("未知的接口状态 %q，可选值: %s", s, strings.Join(valid, ", "))
+}
+
+// Name 接口状态在 Apifox 中显示的中文名称
+func (s Status) Name() string {
+	for name, status := range statusAliases {
+		if status == s && !isASCII(name) {
+			return name
+		}
+	}
+	return string(s)
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText 解析状态代码或别名，用于读取配置文件
+func (s *Status) UnmarshalText(text []byte) error {
+	status, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// StatusRule 接口状态的生命周期规则：指定状态的接口不能依赖（@depends）处于禁止状态的接口。
+//
+//	例如：已发布（released）的接口不能依赖设计中（designing）的接口。
+type StatusRule struct {
+	Status          Status   `json:"status"`          // 接口状态
+	DisallowDepends []Status `json:"disallowDepends"` // 依赖的接口不能处于这些状态
+}
+
+// CheckStatusRules 检查接口之间的依赖是否符合生命周期规则，返回所有不符合规则的错误。
+//
+//	只检查有规则适用的接口，这些接口依赖的接口不存在时也会返回错误。
+func CheckStatusRules(items []ApiItem, rules []StatusRule) []error {
+	errs := make([]error, 0)
+	index := make(map[string]*ApiItem)
+	for i := range items {
+		index[items[i].Endpoint()] = &items[i]
+	}
+	for i := range items {
+		item := &items[i]
+		disallows := make([]Status, 0)
+		for _, rule := range rules {
+			if rule.Status == item.Status {
+				disallows = append(disallows, rule.DisallowDepends...)
+			}
+		}
+		if len(disallows) == 0 {
+			continue
+		}
+
+		location := item.Endpoint()
+		if source := item.Source.String(); source != "" {
+			location += "（" + source + "）"
+		}
+		for _, depend := range item.Depends {
+			dep, ok := index[depend]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s 依赖的接口 %s 不存在", location, depend))
+				continue
+			}
+			for _, disallow := range disallows {
+				if dep.Status == disallow {
+					errs = append(errs, fmt.Errorf("%s 的状态为%s，不能依赖%s的接口 %s",
+						location, item.Status.Name(), dep.Status.Name(), depend))
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	Convey("测试解析接口状态", t, func() {
+		for s, want := range map[string]Status{
+			"":          "",
+			"released":  StatusReleased,
+			" Testing ": StatusTesting,
+			"已发布":       StatusReleased,
+			"将废弃":       StatusDeprecated,
+			"已废弃":       StatusObsolete,
+			"dev":       StatusDeveloping,
+		} {
+			status, err := ParseStatus(s)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, want)
+		}
+
+		_, err := ParseStatus("relesed")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "designing")
+
+		So(StatusIntegrating.Name(), ShouldEqual, "联调中")
+
+		p := NewParser()
+		apiItem := &ApiItem{}
+		So(p.parseGoComment(apiItem, nil, "", "// @status 测试中"), ShouldBeNil)
+		So(apiItem.Status, ShouldEqual, StatusTesting)
+		So(p.parseGoComment(apiItem, nil, "", "// @status relesed"), ShouldNotBeNil)
+	})
+
+	Convey("测试从配置中读取规则", t, func() {
+		var rule StatusRule
+		err := json.Unmarshal([]byte(`{"status": "已发布", "disallowDepends": ["designing", "待确定"]}`), &rule)
+		So(err, ShouldBeNil)
+		So(rule.Status, ShouldEqual, StatusReleased)
+		So(rule.DisallowDepends, ShouldResemble, []Status{StatusDesigning, StatusPending})
+
+		err = json.Unmarshal([]byte(`{"status": "unknown"}`), &rule)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestCheckStatusRules(t *testing.T) {
+	Convey("测试检查接口状态的生命周期规则", t, func() {
+		p := NewParser()
+		order := ApiItem{Status: StatusReleased, Source: &Source{File: "order.go", Line: 10, Func: "GetOrder"}}
+		So(p.parseGoComment(&order, nil, "", "// @url GET /order/{id}"), ShouldBeNil)
+		So(p.parseGoComment(&order, nil, "", "// @depends get /pet/{id}"), ShouldBeNil)
+		So(p.parseGoComment(&order, nil, "", "// @depends GET /user"), ShouldBeNil)
+		So(order.Depends, ShouldResemble, []string{"GET /pet/{id}", "GET /user"})
+
+		items := []ApiItem{
+			order,
+			{Method: "get", Path: "/pet/{id}", Status: StatusDesigning},
+			{Method: "get", Path: "/user", Status: StatusReleased},
+		}
+		rules := []StatusRule{{Status: StatusReleased, DisallowDepends: []Status{StatusDesigning, StatusPending}}}
+		So(CheckStatusRules(items, nil), ShouldBeEmpty)
+
+		errs := CheckStatusRules(items, rules)
+		So(errs, ShouldHaveLength, 1)
+		So(errs[0].Error(), ShouldEqual, "GET /order/{id}（order.go:10 GetOrder()） 的状态为已发布，不能依赖设计中的接口 GET /pet/{id}")
+
+		errs = CheckStatusRules(items[:1], rules)
+		So(errs, ShouldHaveLength, 2)
+		So(errs[0].Error(), ShouldContainSubstring, "不存在")
+
+		// 没有规则适用时，不检查依赖的接口是否存在
+		So(CheckStatusRules(items[:1], nil), ShouldBeEmpty)
+		order.Status = StatusDesigning
+		So(CheckStatusRules([]ApiItem{order}, rules), ShouldBeEmpty)
+	})
+}
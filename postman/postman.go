@@ -0,0 +1,307 @@
+// Package postman 将接口文档转换为 Postman Collection v2.1（https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html）。
+package postman
+
+import (
+	"encoding/json"
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/sample"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	Schema      = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	VarBaseUrl  = "baseUrl"                // 接口地址变量
+	DefaultHost = "{{" + VarBaseUrl + "}}" // 请求地址的 host 部分
+)
+
+// Collection Postman 集合
+type Collection struct {
+	Info     Info        `json:"info"`
+	Item     []*Item     `json:"item"`
+	Variable []*Variable `json:"variable,omitempty"`
+}
+
+// Info 集合信息
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// Item 目录或请求，目录只有 Name 和 Item
+type Item struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Item        []*Item     `json:"item,omitempty"`     // 目录中的子目录和请求
+	Request     *Request    `json:"request,omitempty"`  // 请求
+	Response    []*Response `json:"response,omitempty"` // 保存的响应示例
+}
+
+// Request 请求
+type Request struct {
+	Method      string      `json:"method"`
+	Header      []*KeyValue `json:"header"`
+	Url         *Url        `json:"url"`
+	Body        *Body       `json:"body,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// Url 请求地址
+type Url struct {
+	Raw      string      `json:"raw"`
+	Host     []string    `json:"host"`
+	Path     []string    `json:"path"`
+	Query    []*KeyValue `json:"query,omitempty"`
+	Variable []*KeyValue `json:"variable,omitempty"` // 路径参数，如：:petId
+}
+
+// KeyValue 请求头、查询参数、路径参数和表单参数
+type KeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"` // 表单参数的类型：text 或 file
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"` // 选填参数默认不启用
+}
+
+// Body 请求体
+type Body struct {
+	Mode       string       `json:"mode"` // raw、urlencoded、formdata
+	Raw        string       `json:"raw,omitempty"`
+	Urlencoded []*KeyValue  `json:"urlencoded,omitempty"`
+	Formdata   []*KeyValue  `json:"formdata,omitempty"`
+	Options    *BodyOptions `json:"options,omitempty"`
+}
+
+// BodyOptions 请求体选项
+type BodyOptions struct {
+	Raw struct {
+		Language string `json:"language"` // json、xml、text
+	} `json:"raw"`
+}
+
+// Response 保存的响应示例
+type Response struct {
+	Name            string      `json:"name"`
+	OriginalRequest *Request    `json:"originalRequest"`
+	Status          string      `json:"status"`
+	Code            int         `json:"code"`
+	Header          []*KeyValue `json:"header"`
+	Body            string      `json:"body"`
+	PreviewLanguage string      `json:"_postman_previewlanguage,omitempty"`
+}
+
+// Variable 集合变量
+type Variable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// variableRegexp 匹配示例值中的 Postman 变量，如：bearer {{TOKEN}}
+var variableRegexp = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+
+// NewCollection 将接口文档转换为 Postman 集合：目录转换为嵌套的目录，参数和示例值转换为请求字段，响应转换为保存的示例。
+//
+//	示例值中的 {{TOKEN}} 保持不变，并声明为集合变量；请求地址使用 {{baseUrl}} 变量，值为 baseUrl。
+func NewCollection(name, baseUrl string, items []parser.ApiItem) *Collection {
+	c := &Collection{
+		Info: Info{Name: name, Schema: Schema},
+		Item: make([]*Item, 0),
+	}
+	vars := map[string]bool{}
+	for i := range items {
+		apiItem := &items[i]
+		folder := c.folder(apiItem.Folder)
+		req := newRequest(apiItem)
+		*folder = append(*folder, &Item{
+			Name:     apiItem.Title,
+			Request:  req,
+			Response: newResponses(apiItem, req),
+		})
+		collectVariables(req, vars)
+	}
+
+	c.Variable = append(c.Variable, &Variable{Key: VarBaseUrl, Value: baseUrl, Type: "string"})
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if name != VarBaseUrl {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.Variable = append(c.Variable, &Variable{Key: name, Value: "", Type: "string"})
+	}
+	return c
+}
+
+// folder 获取目录中的子项，不存在时按层级创建；没有目录时返回集合的根目录。
+func (c *Collection) folder(folder parser.Folder) *[]*Item {
+	items := &c.Item
+	for _, name := range folder {
+		var sub *Item
+		for _, it := range *items {
+			if it.Request == nil && it.Name == name {
+				sub = it
+				break
+			}
+		}
+		if sub == nil {
+			sub = &Item{Name: name, Item: make([]*Item, 0)}
+			*items = append(*items, sub)
+		}
+		items = &sub.Item
+	}
+	return items
+}
+
+// description 接口说明和备注
+func description(apiItem *parser.ApiItem) string {
+	parts := make([]string, 0, 2)
+	if apiItem.Description != "" {
+		parts = append(parts, apiItem.Description)
+	}
+	if apiItem.Remark != "" {
+		parts = append(parts, apiItem.Remark)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func newRequest(apiItem *parser.ApiItem) *Request {
+	req := &Request{
+		Method:      strings.ToUpper(apiItem.Method),
+		Header:      make([]*KeyValue, 0),
+		Url:         newUrl(apiItem),
+		Description: description(apiItem),
+	}
+	for _, param := range apiItem.Parameters.Header {
+		req.Header = append(req.Header, newKeyValue(param))
+	}
+	if len(apiItem.Parameters.Cookie) > 0 {
+		cookies := make([]string, 0, len(apiItem.Parameters.Cookie))
+		for _, param := range apiItem.Parameters.Cookie {
+			cookies = append(cookies, param.Name+"="+sample.Parameter(param))
+		}
+		req.Header = append(req.Header, &KeyValue{Key: "Cookie", Value: strings.Join(cookies, "; ")})
+	}
+
+	switch apiItem.Parameters.BodyType {
+	case parser.BodyTypeFormUrlEncoded:
+		req.Body = &Body{Mode: "urlencoded", Urlencoded: newFormParams(apiItem.Parameters.FormData, false)}
+	case parser.BodyTypeFormData:
+		req.Body = &Body{Mode: "formdata", Formdata: newFormParams(apiItem.Parameters.FormData, true)}
+	case "", parser.BodyTypeNone:
+	default:
+		if apiItem.Parameters.JsonSchema == nil {
+			break
+		}
+		req.Body = &Body{Mode: "raw", Raw: sample.JSON(apiItem.Parameters.JsonSchema), Options: &BodyOptions{}}
+		req.Body.Options.Raw.Language = "json"
+		req.Header = append(req.Header, &KeyValue{Key: "Content-Type", Value: parser.BodyTypeJSON})
+	}
+	return req
+}
+
+// newUrl 路径参数 {petId} 转换为 Postman 格式 :petId
+func newUrl(apiItem *parser.ApiItem) *Url {
+	path := parser.ReplacePathParams(apiItem.Path, func(name string) string {
+		return ":" + name
+	})
+	u := &Url{
+		Host: []string{DefaultHost},
+		Path: make([]string, 0),
+	}
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			u.Path = append(u.Path, segment)
+		}
+	}
+	raw := DefaultHost + "/" + strings.Join(u.Path, "/")
+
+	queries := make([]string, 0, len(apiItem.Parameters.Query))
+	for _, param := range apiItem.Parameters.Query {
+		kv := newKeyValue(param)
+		u.Query = append(u.Query, kv)
+		if !kv.Disabled {
+			queries = append(queries, kv.Key+"="+kv.Value)
+		}
+	}
+	if len(queries) > 0 {
+		raw += "?" + strings.Join(queries, "&")
+	}
+	u.Raw = raw
+
+	for _, param := range apiItem.Parameters.Path {
+		u.Variable = append(u.Variable, &KeyValue{Key: param.Name, Value: sample.Parameter(param), Description: param.Description})
+	}
+	return u
+}
+
+func newKeyValue(param parser.Parameter) *KeyValue {
+	return &KeyValue{
+		Key:         param.Name,
+		Value:       sample.Parameter(param),
+		Description: param.Description,
+		Disabled:    !param.Required,
+	}
+}
+
+func newFormParams(params []parser.Parameter, formData bool) []*KeyValue {
+	kvs := make([]*KeyValue, 0, len(params))
+	for _, param := range params {
+		kv := newKeyValue(param)
+		if formData {
+			kv.Type = "text"
+			if param.Type == "file" {
+				kv.Type = "file"
+				kv.Value = ""
+			}
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs
+}
+
+// newResponses 每个响应状态码生成一个保存的示例
+func newResponses(apiItem *parser.ApiItem, req *Request) []*Response {
+	contentType := apiItem.ContentType
+	if contentType == "" {
+		contentType = parser.BodyTypeJSON
+	}
+	resps := make([]*Response, 0, len(apiItem.Responses))
+	for _, resp := range apiItem.Responses {
+		r := &Response{
+			Name:            resp.Name,
+			OriginalRequest: req,
+			Status:          http.StatusText(resp.Code),
+			Code:            resp.Code,
+			Header:          []*KeyValue{{Key: "Content-Type", Value: contentType}},
+		}
+		if r.Name == "" {
+			r.Name = r.Status
+		}
+		if resp.JsonSchema != nil {
+			r.Body = sample.JSON(resp.JsonSchema)
+			r.PreviewLanguage = "json"
+		}
+		resps = append(resps, r)
+	}
+	return resps
+}
+
+// collectVariables 收集请求中用到的变量
+func collectVariables(req *Request, vars map[string]bool) {
+	data, _ := json.Marshal(req)
+	for _, match := range variableRegexp.FindAllSubmatch(data, -1) {
+		vars[string(match[1])] = true
+	}
+}
+
+// JSON 生成格式化后的集合 json 数据
+func (c *Collection) JSON() ([]byte, error) {
+	return json.MarshalIndent(c, "", "    ")
+}
@@ -0,0 +1,87 @@
+package postman
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"testing"
+)
+
+func TestNewCollection(t *testing.T) {
+	Convey("测试生成 Postman 集合", t, func() {
+		items := []parser.ApiItem{
+			{
+				Title:  "查询宠物详情",
+				Folder: parser.Folder{"宠物商城", "宠物管理"},
+				Method: "get",
+				Path:   "/pet/{petId}",
+				Parameters: parser.Parameters{
+					Path:   []parser.Parameter{{Name: "petId", Type: "int", Required: true}},
+					Query:  []parser.Parameter{{Name: "lang", Type: "string", Example: "zh"}},
+					Header: []parser.Parameter{{Name: "Authorization", Type: "string", Required: true, Example: "bearer {{TOKEN}}"}},
+				},
+				Responses: []*parser.Response{{Code: 200, Name: "成功", JsonSchema: spec.StringProperty()}},
+			},
+			{
+				Title:  "新建宠物信息",
+				Folder: parser.Folder{"宠物商城", "宠物管理"},
+				Method: "post",
+				Path:   "/pet",
+				Parameters: parser.Parameters{
+					BodyType: parser.BodyTypeFormData,
+					FormData: []parser.Parameter{{Name: "name", Type: "string", Required: true}, {Name: "photo", Type: "file"}},
+				},
+			},
+			{
+				Title:  "登录",
+				Method: "post",
+				Path:   "/login",
+				Parameters: parser.Parameters{
+					BodyType:   parser.BodyTypeJSON,
+					JsonSchema: spec.MapProperty(spec.StringProperty()),
+				},
+			},
+		}
+		c := NewCollection("petshop", "http://localhost:8080", items)
+		So(c.Info.Name, ShouldEqual, "petshop")
+		So(c.Info.Schema, ShouldEqual, Schema)
+
+		Convey("目录转换为嵌套的目录", func() {
+			So(len(c.Item), ShouldEqual, 2)
+			So(c.Item[0].Name, ShouldEqual, "宠物商城")
+			So(c.Item[0].Request, ShouldBeNil)
+			So(c.Item[0].Item[0].Name, ShouldEqual, "宠物管理")
+			So(len(c.Item[0].Item[0].Item), ShouldEqual, 2)
+			So(c.Item[1].Name, ShouldEqual, "登录")
+		})
+
+		Convey("参数转换为请求字段", func() {
+			get := c.Item[0].Item[0].Item[0]
+			So(get.Request.Method, ShouldEqual, "GET")
+			So(get.Request.Url.Raw, ShouldEqual, "{{baseUrl}}/pet/:petId")
+			So(get.Request.Url.Path, ShouldResemble, []string{"pet", ":petId"})
+			So(get.Request.Url.Variable[0].Value, ShouldEqual, "1")
+			So(get.Request.Url.Query[0].Value, ShouldEqual, "zh")
+			So(get.Request.Url.Query[0].Disabled, ShouldBeTrue)
+			So(get.Request.Header[0].Value, ShouldEqual, "bearer {{TOKEN}}")
+			So(len(get.Response), ShouldEqual, 1)
+			So(get.Response[0].Code, ShouldEqual, 200)
+			So(get.Response[0].Body, ShouldEqual, `"string"`)
+
+			post := c.Item[0].Item[0].Item[1]
+			So(post.Request.Body.Mode, ShouldEqual, "formdata")
+			So(post.Request.Body.Formdata[0].Value, ShouldEqual, "string")
+			So(post.Request.Body.Formdata[1].Type, ShouldEqual, "file")
+
+			login := c.Item[1]
+			So(login.Request.Body.Mode, ShouldEqual, "raw")
+			So(login.Request.Body.Options.Raw.Language, ShouldEqual, "json")
+		})
+
+		Convey("示例值中的变量声明为集合变量", func() {
+			So(len(c.Variable), ShouldEqual, 2)
+			So(*c.Variable[0], ShouldResemble, Variable{Key: VarBaseUrl, Value: "http://localhost:8080", Type: "string"})
+			So(c.Variable[1].Key, ShouldEqual, "TOKEN")
+		})
+	})
+}
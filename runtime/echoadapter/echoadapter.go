@@ -0,0 +1,17 @@
+// Package echoadapter 将 runtime.Validator 适配为 Echo 中间件。
+//
+// 使用示例：
+//
+//	e := echo.New()
+//	e.Use(echoadapter.Middleware(runtime.NewValidator(spec)))
+package echoadapter
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/whaios/apigo/runtime"
+)
+
+// Middleware Echo 中间件，校验请求；开启 ValidateResponses 时缓存并校验响应
+func Middleware(v *runtime.Validator) echo.MiddlewareFunc {
+	return echo.WrapMiddleware(v.Middleware)
+}
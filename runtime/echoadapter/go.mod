@@ -0,0 +1,31 @@
+module github.com/whaios/apigo/runtime/echoadapter
+
+go 1.19
+
+// 本地开发时使用当前仓库中的代码。发布时先给 apigo 打版本标签（如 v1.2.0），
+// 再将下面 require 中的 v0.0.0 改为该版本，然后给本模块打标签（如 runtime/echoadapter/v1.2.0）。
+replace github.com/whaios/apigo => ../..
+
+require (
+	github.com/labstack/echo/v4 v4.11.4
+	github.com/whaios/apigo v0.0.0
+)
+
+require (
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/jsonreference v0.20.0 // indirect
+	github.com/go-openapi/spec v0.20.8 // indirect
+	github.com/go-openapi/swag v0.19.15 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/net v0.19.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
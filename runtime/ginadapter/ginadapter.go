@@ -0,0 +1,80 @@
+// Package ginadapter 将 runtime.Validator 适配为 Gin 中间件。
+//
+// 使用示例：
+//
+//	router := gin.New()
+//	router.Use(ginadapter.Middleware(runtime.NewValidator(spec)))
+package ginadapter
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/whaios/apigo/runtime"
+)
+
+// Middleware Gin 中间件，校验请求；开启 ValidateResponses 时缓存并校验响应，处理器主动刷新的响应不校验
+func Middleware(v *runtime.Validator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !v.Check(c.Writer, c.Request) {
+			c.Abort()
+			return
+		}
+		if !v.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		w := &responseWriter{ResponseWriter: c.Writer, rec: runtime.NewResponseRecorder(c.Writer)}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		rec := w.rec
+		if rec.Flushed() {
+			return
+		}
+		if err := v.ValidateResponse(c.Request.Method, c.Request.URL.Path, rec.Status(), rec.Header(), rec.Body()); err != nil {
+			v.ErrorHandler(c.Writer, c.Request, err)
+			return
+		}
+		rec.Commit()
+	}
+}
+
+// responseWriter 将响应写入缓存
+type responseWriter struct {
+	gin.ResponseWriter
+	rec  *runtime.ResponseRecorder
+	size int
+}
+
+func (w *responseWriter) Header() http.Header { return w.rec.Header() }
+
+func (w *responseWriter) WriteHeader(code int) { w.rec.WriteHeader(code) }
+
+func (w *responseWriter) WriteHeaderNow() {}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	n, err := w.rec.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *responseWriter) Status() int { return w.rec.Status() }
+
+func (w *responseWriter) Size() int {
+	if w.size == 0 {
+		return -1
+	}
+	return w.size
+}
+
+func (w *responseWriter) Written() bool { return w.size > 0 }
+
+// Flush 处理器主动刷新时（如流式响应），写出已缓存的内容，不再校验该响应
+func (w *responseWriter) Flush() { w.rec.Flush() }
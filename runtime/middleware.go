@@ -0,0 +1,424 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxBodyBytes 默认允许读取的最大请求体
+const DefaultMaxBodyBytes = 10 << 20
+
+// NewValidator 创建校验器，默认只校验请求
+func NewValidator(s *Spec) *Validator {
+	return &Validator{
+		Spec:         s,
+		MaxBodyBytes: DefaultMaxBodyBytes,
+		ErrorHandler: DefaultErrorHandler,
+	}
+}
+
+// Validator 根据文档校验请求和响应
+type Validator struct {
+	Spec *Spec
+
+	// ValidateResponses 是否校验响应（测试模式），开启后中间件会缓存完整的响应内容，校验通过后才写出
+	ValidateResponses bool
+	// IgnoreUndocumented 忽略文档中没有的接口，否则返回 404 错误
+	IgnoreUndocumented bool
+	// MaxBodyBytes 校验时允许读取的最大请求体（字节），超过时返回 413 错误，小于等于 0 时不限制
+	MaxBodyBytes int64
+	// ErrorHandler 校验不通过时输出错误
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// RequestError 请求校验不通过
+type RequestError struct {
+	Method, Path string
+	Err          error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("请求 %s %s 与文档不一致: %s", e.Method, e.Path, e.Err)
+}
+
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// ResponseError 响应校验不通过
+type ResponseError struct {
+	Method, Path string
+	Status       int
+	Err          error
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("响应 %s %s %d 与文档不一致: %s", e.Method, e.Path, e.Status, e.Err)
+}
+
+func (e *ResponseError) Unwrap() error { return e.Err }
+
+// ErrUndocumented 文档中没有该接口
+var ErrUndocumented = &ValidationError{Errors: []FieldError{{Message: "文档中没有该接口"}}}
+
+// ErrBodyTooLarge 请求体超过 MaxBodyBytes
+var ErrBodyTooLarge = &ValidationError{Errors: []FieldError{{Pointer: "body", Message: "请求体过大"}}}
+
+// DefaultErrorHandler 请求错误返回 400（文档中没有的接口返回 404，请求体过大返回 413），响应错误返回 500，内容为 json 格式的错误信息
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusBadRequest
+	var detail error = err
+	switch e := err.(type) {
+	case *RequestError:
+		switch e.Err {
+		case ErrUndocumented:
+			code = http.StatusNotFound
+		case ErrBodyTooLarge:
+			code = http.StatusRequestEntityTooLarge
+		}
+		detail = e.Err
+	case *ResponseError:
+		code = http.StatusInternalServerError
+		detail = e.Err
+	}
+
+	body := struct {
+		Error  string       `json:"error"`
+		Errors []FieldError `json:"errors,omitempty"`
+	}{Error: err.Error()}
+	if ve, ok := detail.(*ValidationError); ok {
+		body.Errors = ve.Errors
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Middleware net/http 中间件。
+//
+//	Echo 中可以使用 echo.WrapMiddleware(v.Middleware)，Gin 中可以使用 ginadapter 包。
+//	处理器调用 http.Flusher 主动刷新（如流式响应）时，不再缓存和校验该响应。
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !v.Check(w, r) {
+			return
+		}
+		if !v.ValidateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := NewResponseRecorder(w)
+		next.ServeHTTP(rec, r)
+		if rec.Flushed() {
+			return
+		}
+		if err := v.ValidateResponse(r.Method, r.URL.Path, rec.Status(), rec.Header(), rec.Body()); err != nil {
+			v.ErrorHandler(w, r, err)
+			return
+		}
+		rec.Commit()
+	})
+}
+
+// Check 校验请求，不通过时使用 ErrorHandler 输出错误并返回 false，用于适配其他 Web 框架
+func (v *Validator) Check(w http.ResponseWriter, r *http.Request) bool {
+	if err := v.ValidateRequest(r); err != nil {
+		if v.IgnoreUndocumented && err.(*RequestError).Err == ErrUndocumented {
+			return true
+		}
+		v.ErrorHandler(w, r, err)
+		return false
+	}
+	return true
+}
+
+// ValidateRequest 校验请求参数和请求体，返回 *RequestError。读取请求体后会重新设置 r.Body，不影响后续处理。
+//
+//	请求体超过 MaxBodyBytes 时返回 ErrBodyTooLarge。
+func (v *Validator) ValidateRequest(r *http.Request) error {
+	op, pathParams, ok := v.Spec.FindOperation(r.Method, r.URL.Path)
+	if !ok {
+		return &RequestError{Method: r.Method, Path: r.URL.Path, Err: ErrUndocumented}
+	}
+
+	var body []byte
+	if r.Body != nil {
+		reader := r.Body
+		if v.MaxBodyBytes > 0 {
+			reader = http.MaxBytesReader(nil, r.Body, v.MaxBodyBytes)
+		}
+		var err error
+		if body, err = io.ReadAll(reader); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				err = ErrBodyTooLarge
+			}
+			return &RequestError{Method: r.Method, Path: r.URL.Path, Err: err}
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	errs := make([]FieldError, 0)
+	form := parseForm(r, body)
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var values []string
+		switch param.In {
+		case "path":
+			if val, ok := pathParams[param.Name]; ok {
+				values = []string{val}
+			}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			values = r.Header.Values(param.Name)
+		case "cookie":
+			if c, err := r.Cookie(param.Name); err == nil {
+				values = []string{c.Value}
+			}
+		case "formData":
+			values = form[param.Name]
+		case "body":
+			errs = append(errs, v.validateBody(r, param, body)...)
+			continue
+		}
+		errs = append(errs, validateParam(param, values)...)
+	}
+	if err := errorOrNil(errs); err != nil {
+		return &RequestError{Method: r.Method, Path: r.URL.Path, Err: err}
+	}
+	return nil
+}
+
+func (v *Validator) validateBody(r *http.Request, param spec.Parameter, body []byte) []FieldError {
+	if param.Schema == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if param.Required || len(param.Schema.Required) > 0 {
+			return []FieldError{{Pointer: "body", Message: "缺少请求体"}}
+		}
+		return nil
+	}
+	if !isJSON(mediaType(r.Header.Get("Content-Type"))) {
+		return nil
+	}
+	if err := ValidateJSON(v.Spec.Doc, param.Schema, body); err != nil {
+		return err.(*ValidationError).Errors
+	}
+	return nil
+}
+
+// ValidateResponse 校验响应内容，返回 *ResponseError。path 可以是实际的请求路径，也可以是文档中定义的路径。
+//
+//	响应状态码没有在文档中定义时，使用 default 响应，没有则报错。
+//	只校验 json 格式的响应内容，文档中定义接口返回 json 时（没有定义默认为 json），也会按 json 校验。
+func (v *Validator) ValidateResponse(method, path string, status int, header http.Header, body []byte) error {
+	newErr := func(err error) error {
+		return &ResponseError{Method: method, Path: path, Status: status, Err: err}
+	}
+	op, _, ok := v.Spec.FindOperation(method, path)
+	if !ok {
+		return newErr(ErrUndocumented)
+	}
+
+	resp, ok := FindResponse(op, status)
+	if !ok {
+		return newErr(&ValidationError{Errors: []FieldError{{Message: fmt.Sprintf("文档中没有定义 %d 响应", status)}}})
+	}
+	if resp.Schema == nil {
+		return nil
+	}
+	if header != nil && !isJSON(mediaType(header.Get("Content-Type"))) && !v.producesJSON(op) {
+		return nil
+	}
+	if err := ValidateJSON(v.Spec.Doc, resp.Schema, body); err != nil {
+		return newErr(err)
+	}
+	return nil
+}
+
+// FindResponse 根据状态码查找文档中定义的响应，没有找到时使用 default 响应
+func FindResponse(op *Operation, status int) (*spec.Response, bool) {
+	if op.Responses == nil {
+		return nil, false
+	}
+	if resp, ok := op.Responses.StatusCodeResponses[status]; ok {
+		return &resp, true
+	}
+	if op.Responses.Default != nil {
+		return op.Responses.Default, true
+	}
+	return nil, false
+}
+
+// producesJSON 文档中定义的接口响应类型是否为 json，没有定义默认为 json
+func (v *Validator) producesJSON(op *Operation) bool {
+	produces := op.Produces
+	if len(produces) == 0 {
+		produces = v.Spec.Doc.Produces
+	}
+	if len(produces) == 0 {
+		return true
+	}
+	for _, mt := range produces {
+		if isJSON(mediaType(mt)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func validateParam(param spec.Parameter, values []string) []FieldError {
+	pointer := param.In + "." + param.Name
+	if len(values) == 0 || (len(values) == 1 && values[0] == "" && param.In != "query") {
+		if param.Required {
+			return []FieldError{{Pointer: pointer, Message: "缺少必填参数"}}
+		}
+		return nil
+	}
+	tpe := NormalizeType(param.Type)
+	for _, val := range values {
+		var err error
+		switch tpe {
+		case "integer":
+			_, err = strconv.ParseInt(val, 10, 64)
+		case "number":
+			_, err = strconv.ParseFloat(val, 64)
+		case "boolean":
+			_, err = strconv.ParseBool(val)
+		}
+		if err != nil {
+			return []FieldError{{Pointer: pointer, Message: fmt.Sprintf("类型应为 %s，实际值为 %q", tpe, val)}}
+		}
+		if len(param.Enum) > 0 && !enumContains(param.Enum, val) {
+			return []FieldError{{Pointer: pointer, Message: fmt.Sprintf("值不在可选范围内 %v", param.Enum)}}
+		}
+	}
+	return nil
+}
+
+// parseForm 从请求体中解析表单参数，不影响原请求
+func parseForm(r *http.Request, body []byte) url.Values {
+	form := make(url.Values)
+	ct := r.Header.Get("Content-Type")
+	switch mediaType(ct) {
+	case "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			form = values
+		}
+	case "multipart/form-data":
+		_, params, _ := mime.ParseMediaType(ct)
+		mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			data, _ := io.ReadAll(part)
+			if part.FileName() != "" {
+				data = []byte(part.FileName())
+			}
+			form.Add(part.FormName(), string(data))
+		}
+	}
+	return form
+}
+
+func mediaType(contentType string) string {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return strings.ToLower(mt)
+}
+
+// NewResponseRecorder 创建响应缓存，调用 Commit 后才写出到 w
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{w: w, header: w.Header().Clone()}
+}
+
+// ResponseRecorder 缓存响应内容，用于校验响应。
+//
+//	实现了 http.Flusher，处理器主动刷新时写出已缓存的内容，之后的内容直接写出，不再缓存。
+type ResponseRecorder struct {
+	w         http.ResponseWriter
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	committed bool // 已经写出缓存的响应
+	flushed   bool // 处理器主动刷新过，响应不完整，不能再校验
+}
+
+func (r *ResponseRecorder) Header() http.Header { return r.header }
+
+func (r *ResponseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if r.flushed {
+		return r.w.Write(data)
+	}
+	return r.body.Write(data)
+}
+
+func (r *ResponseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// Status 响应状态码
+func (r *ResponseRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Body 响应内容
+func (r *ResponseRecorder) Body() []byte { return r.body.Bytes() }
+
+// Flushed 处理器是否主动刷新过响应，刷新后的响应已经写出，不能再校验
+func (r *ResponseRecorder) Flushed() bool { return r.flushed }
+
+// Flush 实现 http.Flusher，写出已缓存的响应，之后的内容直接写出
+func (r *ResponseRecorder) Flush() {
+	r.flushed = true
+	r.Commit()
+	if f, ok := r.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Commit 将缓存的响应写出，多次调用只写出一次
+func (r *ResponseRecorder) Commit() {
+	if r.committed {
+		return
+	}
+	r.committed = true
+
+	dst := r.w.Header()
+	for k := range dst {
+		if _, ok := r.header[k]; !ok {
+			dst.Del(k)
+		}
+	}
+	for k, v := range r.header {
+		dst[k] = v
+	}
+	r.w.WriteHeader(r.Status())
+	_, _ = r.w.Write(r.body.Bytes())
+	r.body.Reset()
+}
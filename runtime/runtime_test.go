@@ -0,0 +1,127 @@
+package runtime
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	Convey("测试校验请求和响应", t, func() {
+		s, err := LoadFile("../example/openapi_data.json")
+		So(err, ShouldBeNil)
+
+		var respBody string
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(respBody))
+		})
+		v := NewValidator(s)
+		v.ValidateResponses = true
+		h := v.Middleware(handler)
+
+		serve := func(req *http.Request) (*httptest.ResponseRecorder, map[string]interface{}) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			body := make(map[string]interface{})
+			_ = json.Unmarshal(rec.Body.Bytes(), &body)
+			return rec, body
+		}
+
+		// ---- 缺少必填参数 --------------------
+		rec, body := serve(httptest.NewRequest(http.MethodGet, "/pet/findByStatus", nil))
+		So(rec.Code, ShouldEqual, http.StatusBadRequest)
+		So(body["errors"], ShouldResemble, []interface{}{
+			map[string]interface{}{"pointer": "query.status", "message": "缺少必填参数"},
+			map[string]interface{}{"pointer": "header.Authorization", "message": "缺少必填参数"},
+		})
+
+		// ---- 参数类型错误 --------------------
+		req := httptest.NewRequest(http.MethodGet, "/pet/abc", nil)
+		req.Header.Set("Authorization", "bearer")
+		rec, _ = serve(req)
+		So(rec.Code, ShouldEqual, http.StatusBadRequest)
+		So(rec.Body.String(), ShouldContainSubstring, "path.petId")
+
+		// ---- 请求体校验 --------------------
+		req = httptest.NewRequest(http.MethodPut, "/pet", strings.NewReader(`{"id":"1","name":"Kitty","status":"sold","tags":[{"id":1}]}`))
+		req.Header.Set("Authorization", "bearer")
+		req.Header.Set("Content-Type", "application/json")
+		rec, body = serve(req)
+		So(rec.Code, ShouldEqual, http.StatusBadRequest)
+		So(body["errors"], ShouldResemble, []interface{}{
+			map[string]interface{}{"pointer": "/tags/0/id", "message": "类型应为 string，实际为 integer"},
+		})
+
+		// ---- 响应校验 --------------------
+		respSpec, err := Load([]byte(`{"swagger":"2.0","paths":{"/pet/{petId}":{"get":{"responses":{"200":{"schema":{
+			"allOf":[
+				{"type":"object","properties":{"errcode":{"type":"integer"}}},
+				{"type":"object","properties":{"data":{"type":"object","required":["id","name"],"properties":{
+					"id":{"type":"string"},"name":{"type":"string"},"category":{"type":"object","properties":{"id":{"type":"string"}}}
+				}}}}
+			]}}}}}}}`))
+		So(err, ShouldBeNil)
+		v.Spec = respSpec
+		req = httptest.NewRequest(http.MethodGet, "/pet/1", nil)
+		respBody = `{"errcode":0,"data":{"id":"1"}}`
+		rec, body = serve(req)
+		So(rec.Code, ShouldEqual, http.StatusInternalServerError)
+		So(body["errors"], ShouldResemble, []interface{}{
+			map[string]interface{}{"pointer": "/data/name", "message": "缺少必填字段"},
+		})
+
+		respBody = `{"errcode":0,"data":{"id":"1","name":"Kitty","category":{"id":null}}}`
+		rec, _ = serve(req)
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Body.String(), ShouldEqual, respBody)
+
+		// ---- 文档中没有的接口 --------------------
+		rec, _ = serve(httptest.NewRequest(http.MethodGet, "/user", nil))
+		So(rec.Code, ShouldEqual, http.StatusNotFound)
+		v.IgnoreUndocumented, v.ValidateResponses = true, false
+		rec, _ = serve(httptest.NewRequest(http.MethodGet, "/user", nil))
+		So(rec.Code, ShouldEqual, http.StatusOK)
+	})
+
+	Convey("测试处理器主动刷新响应", t, func() {
+		s, err := Load([]byte(`{"swagger":"2.0","paths":{"/events":{"get":{"responses":{"200":{"schema":{"type":"object"}}}}}}}`))
+		So(err, ShouldBeNil)
+		v := NewValidator(s)
+		v.ValidateResponses = true
+		h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: 1\n\n"))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte("data: 2\n\n"))
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Flushed, ShouldBeTrue)
+		So(rec.Header().Get("Content-Type"), ShouldEqual, "text/event-stream")
+		So(rec.Body.String(), ShouldEqual, "data: 1\n\ndata: 2\n\n")
+	})
+
+	Convey("测试限制请求体大小", t, func() {
+		s, err := Load([]byte(`{"swagger":"2.0","paths":{"/pet":{"post":{"parameters":[{"in":"body","name":"body","schema":{"type":"object"}}],"responses":{"200":{}}}}}}`))
+		So(err, ShouldBeNil)
+		v := NewValidator(s)
+		v.MaxBodyBytes = 8
+		h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		serve := func(body string) int {
+			req := httptest.NewRequest(http.MethodPost, "/pet", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec.Code
+		}
+		So(serve(`{"a":1}`), ShouldEqual, http.StatusOK)
+		So(serve(`{"name":"Kitty"}`), ShouldEqual, http.StatusRequestEntityTooLarge)
+	})
+}
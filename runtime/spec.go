@@ -0,0 +1,153 @@
+// Package runtime 在服务运行时加载生成的 OpenAPI 2.0 文档，校验请求和响应是否与文档一致。
+//
+// 使用示例：
+//
+//	//go:generate apigo apifox --dir ./handler --outfile openapi.json
+//	//go:embed openapi.json
+//	var openapiJson []byte
+//
+//	spec, err := runtime.Load(openapiJson)
+//	...
+//	handler = runtime.NewValidator(spec).Middleware(handler)
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Load 加载 OpenAPI 2.0（Swagger）格式的 json 文档，如 parser.Parse 解析后导出的文档
+func Load(data []byte) (*Spec, error) {
+	doc := &spec.Swagger{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("解析 OpenAPI 文档出错: %s", err)
+	}
+	return New(doc), nil
+}
+
+// LoadFile 加载 OpenAPI 2.0（Swagger）格式的 json 文件
+func LoadFile(file string) (*Spec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Load(data)
+}
+
+// New 根据文档创建路由，路径中的 {param} 匹配任意一段路径，静态路径优先匹配
+func New(doc *spec.Swagger) *Spec {
+	s := &Spec{Doc: doc, routes: make([]*route, 0)}
+	if doc.Paths == nil {
+		return s
+	}
+	paths := make([]string, 0, len(doc.Paths.Paths))
+	for path := range doc.Paths.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths.Paths[path]
+		for method, op := range map[string]*spec.Operation{
+			http.MethodGet:     item.Get,
+			http.MethodPut:     item.Put,
+			http.MethodPost:    item.Post,
+			http.MethodDelete:  item.Delete,
+			http.MethodOptions: item.Options,
+			http.MethodHead:    item.Head,
+			http.MethodPatch:   item.Patch,
+		} {
+			if op != nil {
+				s.routes = append(s.routes, newRoute(&Operation{Method: method, Path: path, Operation: op}))
+			}
+		}
+	}
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		if s.routes[i].static != s.routes[j].static {
+			return s.routes[i].static > s.routes[j].static
+		}
+		return s.routes[i].op.Path < s.routes[j].op.Path
+	})
+	return s
+}
+
+// Spec 加载后的文档
+type Spec struct {
+	Doc    *spec.Swagger
+	routes []*route
+}
+
+// Operation 文档中的接口
+type Operation struct {
+	Method string // http 请求方式，大写
+	Path   string // 文档中定义的路径，如：/pet/{petId}
+	*spec.Operation
+}
+
+type route struct {
+	op       *Operation
+	segments []string
+	static   int
+}
+
+func newRoute(op *Operation) *route {
+	r := &route{op: op, segments: splitPath(op.Path)}
+	for _, seg := range r.segments {
+		if !isParam(seg) {
+			r.static++
+		}
+	}
+	return r
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+// FindOperation 根据请求方式和请求路径查找接口，同时返回路径参数。
+//
+//	path 可以是实际的请求路径，如 /pet/1，也可以是文档中定义的路径，如 /pet/{petId}
+func (s *Spec) FindOperation(method, path string) (*Operation, map[string]string, bool) {
+	if basePath := strings.TrimRight(s.Doc.BasePath, "/"); basePath != "" {
+		path = strings.TrimPrefix(path, basePath)
+	}
+	segments := splitPath(path)
+	for _, r := range s.routes {
+		if !strings.EqualFold(r.op.Method, method) {
+			continue
+		}
+		// 文档中定义的路径
+		if r.op.Path == path {
+			return r.op, map[string]string{}, true
+		}
+		if params, ok := r.match(segments); ok {
+			return r.op, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+func (r *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range r.segments {
+		if isParam(seg) {
+			params[seg[1:len(seg)-1]] = segments[i]
+			continue
+		}
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
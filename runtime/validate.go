@@ -0,0 +1,258 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDepth 校验数据的最大层数，避免递归引用导致死循环
+const maxDepth = 32
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Pointer string `json:"pointer"` // 出错字段的 JSON 指针路径，如：/data/pets/0/name，参数为 参数位置.参数名，如：query.status
+	Message string `json:"message"` // 错误说明
+}
+
+func (e FieldError) String() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+	return e.Pointer + ": " + e.Message
+}
+
+// ValidationError 校验不通过
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.String())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// errorOrNil 没有错误时返回 nil，避免返回包含 nil 指针的 error 接口
+func errorOrNil(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// DecodeJSON 解析 json 数据，数字解析为 json.Number 以便区分整数和小数
+func DecodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ValidateJSON 校验 json 数据是否符合 schema
+func ValidateJSON(doc *spec.Swagger, schema *spec.Schema, data []byte) error {
+	value, err := DecodeJSON(data)
+	if err != nil {
+		return &ValidationError{Errors: []FieldError{{Message: "无效的 json 数据: " + err.Error()}}}
+	}
+	return ValidateValue(doc, schema, value)
+}
+
+// ValidateValue 校验 DecodeJSON 解析后的数据是否符合 schema，doc 用于解析 #/definitions/ 引用，可以为 nil
+func ValidateValue(doc *spec.Swagger, schema *spec.Schema, value interface{}) error {
+	if schema == nil {
+		return nil
+	}
+	v := &validator{doc: doc, errs: make([]FieldError, 0)}
+	v.validate(schema, value, "", 0)
+	return errorOrNil(v.errs)
+}
+
+type validator struct {
+	doc  *spec.Swagger
+	errs []FieldError
+}
+
+func (v *validator) fail(pointer, format string, a ...interface{}) {
+	v.errs = append(v.errs, FieldError{Pointer: pointer, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) resolve(schema *spec.Schema) *spec.Schema {
+	for i := 0; i < maxDepth && v.doc != nil; i++ {
+		ref := schema.Ref.String()
+		if ref == "" {
+			break
+		}
+		def, ok := v.doc.Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+		if !ok {
+			break
+		}
+		schema = &def
+	}
+	return schema
+}
+
+func (v *validator) validate(schema *spec.Schema, value interface{}, pointer string, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	schema = v.resolve(schema)
+
+	for i := range schema.AllOf {
+		v.validate(&schema.AllOf[i], value, pointer, depth+1)
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		v.fail(pointer, "值不在可选范围内 %v", schema.Enum)
+	}
+
+	tpe := ""
+	if len(schema.Type) > 0 {
+		tpe = NormalizeType(schema.Type[0])
+	} else if len(schema.Properties) > 0 {
+		tpe = "object"
+	}
+	if tpe == "" {
+		return
+	}
+	if !typeMatches(tpe, value) {
+		v.fail(pointer, "类型应为 %s，实际为 %s", tpe, jsonType(value))
+		return
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, val, pointer, depth)
+	case []interface{}:
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range val {
+				v.validate(schema.Items.Schema, item, pointer+"/"+strconv.Itoa(i), depth+1)
+			}
+		}
+	}
+}
+
+func (v *validator) validateObject(schema *spec.Schema, obj map[string]interface{}, pointer string, depth int) {
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(pointer+"/"+escapePointer(name), "缺少必填字段")
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		val := obj[name]
+		prop, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+				v.validate(schema.AdditionalProperties.Schema, val, pointer+"/"+escapePointer(name), depth+1)
+			}
+			continue
+		}
+		// 选填字段允许为 null（如 go 代码中的指针类型）
+		if val == nil && !strSliceContains(schema.Required, name) {
+			continue
+		}
+		v.validate(&prop, val, pointer+"/"+escapePointer(name), depth+1)
+	}
+}
+
+// NormalizeType 将 go 数据类型转换为 json schema 类型，如：int64 > integer
+func NormalizeType(tpe string) string {
+	switch tpe {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+		return "integer"
+	case "float32", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	}
+	return tpe
+}
+
+func typeMatches(tpe string, value interface{}) bool {
+	switch tpe {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(n.String(), 10, 64)
+		return err == nil
+	}
+	// 不认识的类型不做校验
+	return true
+}
+
+func jsonType(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := strconv.ParseInt(val.String(), 10, 64); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) || fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapePointer 按 JSON 指针规则转义属性名
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func strSliceContains(opts []string, val string) bool {
+	for _, opt := range opts {
+		if opt == val {
+			return true
+		}
+	}
+	return false
+}
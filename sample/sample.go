@@ -0,0 +1,184 @@
+// Package sample 根据 JSON Schema 生成示例数据，优先使用文档中明确定义的示例值。
+package sample
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	"github.com/whaios/apigo/parser"
+	"strconv"
+)
+
+// 没有示例值时，各数据类型使用的默认值
+const (
+	DefaultString  = "string"
+	DefaultInteger = 1
+	DefaultNumber  = 1.5
+	DefaultBoolean = true
+)
+
+// Field 对象的属性
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Object 保持属性顺序的对象，序列化为 json 对象
+type Object []Field
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Get 获取指定属性值
+func (o Object) Get(key string) (interface{}, bool) {
+	for _, f := range o {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// FromSchema 根据 schema 生成示例数据，对象按属性排序生成 Object。
+func FromSchema(schema *spec.Schema) interface{} {
+	if schema == nil {
+		return nil
+	}
+	return fromSchema(schema, 0)
+}
+
+// JSON 根据 schema 生成格式化后的 json 示例数据
+func JSON(schema *spec.Schema) string {
+	data, err := json.MarshalIndent(FromSchema(schema), "", "    ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func fromSchema(schema *spec.Schema, depth int) interface{} {
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if depth > parser.SchemaMaxDepth {
+		return nil
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		obj := make(Object, 0)
+		for i := range schema.AllOf {
+			if sub, ok := fromSchema(&schema.AllOf[i], depth+1).(Object); ok {
+				obj = merge(obj, sub)
+			}
+		}
+		return obj
+	}
+
+	switch parser.SchemaType(schema) {
+	case parser.OBJECT:
+		return fromObject(schema, depth)
+	case parser.ARRAY:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return []interface{}{}
+		}
+		return []interface{}{fromSchema(schema.Items.Schema, depth+1)}
+	case parser.STRING:
+		return stringValue(schema.Format)
+	case parser.INTEGER:
+		return DefaultInteger
+	case parser.NUMBER:
+		return DefaultNumber
+	case parser.BOOLEAN:
+		return DefaultBoolean
+	}
+	return nil
+}
+
+func fromObject(schema *spec.Schema, depth int) interface{} {
+	obj := make(Object, 0, len(schema.Properties))
+	for _, name := range parser.SchemaPropertyNames(schema) {
+		prop := schema.Properties[name]
+		obj = append(obj, Field{Key: name, Value: fromSchema(&prop, depth+1)})
+	}
+	if len(obj) == 0 && schema.AdditionalProperties != nil {
+		var val interface{} = DefaultString
+		if schema.AdditionalProperties.Schema != nil {
+			val = fromSchema(schema.AdditionalProperties.Schema, depth+1)
+		}
+		obj = append(obj, Field{Key: "key", Value: val})
+	}
+	return obj
+}
+
+// merge 合并对象属性，同名属性使用后面的值
+func merge(dst, src Object) Object {
+	for _, f := range src {
+		replaced := false
+		for i := range dst {
+			if dst[i].Key == f.Key {
+				dst[i].Value = f.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			dst = append(dst, f)
+		}
+	}
+	return dst
+}
+
+func stringValue(format string) string {
+	switch format {
+	case "date-time":
+		return "2006-01-02T15:04:05Z"
+	case "date":
+		return "2006-01-02"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	}
+	return DefaultString
+}
+
+// Parameter 获取参数的示例值，没有定义示例值时按数据类型生成
+func Parameter(param parser.Parameter) string {
+	if param.Example != "" {
+		return param.Example
+	}
+	switch param.Type {
+	case parser.INTEGER, "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return strconv.Itoa(DefaultInteger)
+	case parser.NUMBER, "float32", "float64":
+		return strconv.FormatFloat(DefaultNumber, 'f', -1, 64)
+	case parser.BOOLEAN, "bool":
+		return strconv.FormatBool(DefaultBoolean)
+	}
+	return DefaultString
+}
@@ -0,0 +1,36 @@
+package sample
+
+import (
+	"encoding/json"
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"testing"
+)
+
+func TestFromSchema(t *testing.T) {
+	Convey("测试生成组合类型的示例数据", t, func() {
+		httpCode := spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"errcode": *spec.Int32Property(), "errmsg": *spec.StringProperty()},
+		}}
+		parser.SchemaSetPropertiesOrders(&httpCode, []string{"errcode", "errmsg"})
+		data := spec.Schema{SchemaProps: spec.SchemaProps{
+			Type: []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{
+				"data": *spec.ArrayProperty(spec.DateTimeProperty()),
+			},
+		}}
+
+		got, err := json.Marshal(FromSchema(spec.ComposedSchema(httpCode, data)))
+		So(err, ShouldBeNil)
+		So(string(got), ShouldEqual, `{"errcode":1,"errmsg":"string","data":["2006-01-02T15:04:05Z"]}`)
+	})
+
+	Convey("测试生成参数的示例值", t, func() {
+		So(Parameter(parser.Parameter{Type: "int", Example: "10"}), ShouldEqual, "10")
+		So(Parameter(parser.Parameter{Type: "int"}), ShouldEqual, "1")
+		So(Parameter(parser.Parameter{Type: "bool"}), ShouldEqual, "true")
+		So(Parameter(parser.Parameter{Type: "string"}), ShouldEqual, "string")
+	})
+}
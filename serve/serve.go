@@ -0,0 +1,92 @@
+// Package serve 本地文档服务，提供生成的 OpenAPI 文档和离线的文档页面。
+package serve
+
+import (
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"net/http"
+	"sync"
+)
+
+// 服务路径
+const (
+	PathIndex   = "/"
+	PathOpenApi = "/openapi.json"
+)
+
+//go:embed index.html
+var indexHtml []byte
+
+// StaticHTML 生成内嵌文档数据的静态页面，不需要文档服务，可以直接打开或托管在任意静态存储上。
+func StaticHTML(spec []byte) []byte {
+	// 避免文档内容中的 </script> 提前结束脚本
+	spec = bytes.ReplaceAll(spec, []byte("</"), []byte(`<\/`))
+	var buf bytes.Buffer
+	i := bytes.Index(indexHtml, []byte("<script>"))
+	buf.Write(indexHtml[:i])
+	buf.WriteString("<script>window.APIGO_SPEC = ")
+	buf.Write(spec)
+	buf.WriteString(";</script>\n")
+	buf.Write(indexHtml[i:])
+	return buf.Bytes()
+}
+
+// New 创建文档服务
+func New() *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.mux.HandleFunc(PathIndex, s.handleIndex)
+	s.mux.HandleFunc(PathOpenApi, s.handleOpenApi)
+	return s
+}
+
+// Server 文档服务
+type Server struct {
+	mux *http.ServeMux
+
+	mu   sync.RWMutex
+	spec []byte // OpenAPI 文档 json 数据
+	etag string // 文档内容摘要，页面据此判断文档是否有更新
+}
+
+// SetSpec 更新提供的文档内容
+func (s *Server) SetSpec(data []byte) {
+	sum := sha1.Sum(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spec = data
+	s.etag = `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != PathIndex {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexHtml)
+}
+
+func (s *Server) handleOpenApi(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data, etag := s.spec, s.etag
+	s.mu.RUnlock()
+
+	if data == nil {
+		http.Error(w, "文档还没有生成", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,49 @@
+package serve
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	Convey("测试文档服务", t, func() {
+		s := New()
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathOpenApi, nil))
+		So(rec.Code, ShouldEqual, http.StatusServiceUnavailable)
+
+		s.SetSpec([]byte(`{"swagger":"2.0"}`))
+		rec = httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathOpenApi, nil))
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Body.String(), ShouldEqual, `{"swagger":"2.0"}`)
+
+		// 文档没有变化
+		req := httptest.NewRequest(http.MethodGet, PathOpenApi, nil)
+		req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+		rec = httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		So(rec.Code, ShouldEqual, http.StatusNotModified)
+
+		rec = httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathIndex, nil))
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Header().Get("Content-Type"), ShouldStartWith, "text/html")
+
+		rec = httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/none", nil))
+		So(rec.Code, ShouldEqual, http.StatusNotFound)
+	})
+}
+
+func TestStaticHTML(t *testing.T) {
+	Convey("测试生成静态页面", t, func() {
+		html := string(StaticHTML([]byte(`{"swagger":"2.0","info":{"title":"</script>"}}`)))
+		So(html, ShouldContainSubstring, `<script>window.APIGO_SPEC = {"swagger":"2.0","info":{"title":"<\/script>"}};</script>`)
+		So(strings.Index(html, "window.APIGO_SPEC ="), ShouldBeLessThan, strings.Index(html, "if (window.APIGO_SPEC)"))
+	})
+}
@@ -0,0 +1,68 @@
+package snippet
+
+import (
+	"github.com/whaios/apigo/parser"
+	"regexp"
+	"strings"
+)
+
+// shellVarRegexp shell 变量名中不能使用的字符
+var shellVarRegexp = regexp.MustCompile(`[^\w]`)
+
+// Curl 生成 curl 命令，变量 {{TOKEN}} 转换为 shell 环境变量 ${TOKEN}
+func (p *Request) Curl() string {
+	args := []string{"curl -X " + p.Method + " " + shellWord(p.Url)}
+	for _, h := range p.Headers {
+		args = append(args, "-H "+shellWord(h.Name+": "+h.Value))
+	}
+	switch {
+	case p.BodyType == parser.BodyTypeFormUrlEncoded:
+		for _, f := range p.Form {
+			args = append(args, "--data-urlencode "+shellWord(f.Name+"="+f.Value))
+		}
+	case p.BodyType == parser.BodyTypeFormData:
+		for _, f := range p.Form {
+			if f.File {
+				args = append(args, "-F "+shellWord(f.Name+"=@"+f.Name))
+			} else {
+				args = append(args, "-F "+shellWord(f.Name+"="+f.Value))
+			}
+		}
+	case p.Body != "":
+		args = append(args, "-H "+shellWord("Content-Type: "+p.BodyType), "-d "+shellWord(p.Body))
+	}
+	return strings.Join(args, " \\\n  ")
+}
+
+// CurlScript 生成包含所有请求的 shell 脚本，每个请求前注释接口名称
+func CurlScript(reqs []*Request) string {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString("# 请求地址和示例值中的变量从环境变量中读取，如：host=http://localhost:8080\n")
+	for _, req := range reqs {
+		sb.WriteString("\n# " + req.Name + "\n")
+		sb.WriteString(req.Curl() + "\n")
+	}
+	return sb.String()
+}
+
+// shellWord 转换为 shell 参数：没有变量时使用单引号，有变量时使用双引号并将变量转换为 ${NAME}
+func shellWord(s string) string {
+	locs := variableRegexp.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	var sb strings.Builder
+	sb.WriteString(`"`)
+	last := 0
+	for _, loc := range locs {
+		sb.WriteString(escaper.Replace(s[last:loc[0]]))
+		sb.WriteString("${" + shellVarRegexp.ReplaceAllString(s[loc[2]:loc[3]], "_") + "}")
+		last = loc[1]
+	}
+	sb.WriteString(escaper.Replace(s[last:]))
+	sb.WriteString(`"`)
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package snippet
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/whaios/apigo/parser"
+	"net/url"
+	"strings"
+)
+
+// 生成的文件
+const (
+	EnvFile     = "http-client.env.json" // JetBrains HTTP Client 的环境文件
+	DefaultEnv  = "dev"                  // 环境文件中的环境名称
+	HttpFileExt = ".http"
+	boundary    = "apigo-boundary" // multipart/form-data 的分隔符
+)
+
+// HttpFile 一个目录中所有接口生成的 .http 文件
+type HttpFile struct {
+	Path     string     // 文件路径，按目录层级生成，如：宠物商城/宠物管理.http
+	Requests []*Request // 目录中的请求
+}
+
+// HttpFiles 按接口目录生成 .http 文件，没有目录的接口放在 defaultName.http 中。
+func HttpFiles(reqs []*Request, defaultName string) []*HttpFile {
+	files := make([]*HttpFile, 0)
+	index := make(map[string]*HttpFile)
+	for _, req := range reqs {
+		folder := req.Folder
+		if len(folder) == 0 {
+			folder = parser.Folder{defaultName}
+		}
+		p := folder.FilePath(HttpFileExt)
+		file, ok := index[p]
+		if !ok {
+			file = &HttpFile{Path: p}
+			index[p] = file
+			files = append(files, file)
+		}
+		file.Requests = append(file.Requests, req)
+	}
+	return files
+}
+
+// String 生成 .http 文件内容，请求之间使用 ### 分隔
+func (f *HttpFile) String() string {
+	var sb strings.Builder
+	for i, req := range f.Requests {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(req.Http())
+	}
+	return sb.String()
+}
+
+// Http 生成 .http 文件中的一个请求
+func (p *Request) Http() string {
+	var sb strings.Builder
+	sb.WriteString("### " + p.Title + "\n")
+	for _, line := range strings.Split(strings.TrimSpace(p.Description), "\n") {
+		if line != "" {
+			sb.WriteString("# " + line + "\n")
+		}
+	}
+	sb.WriteString(p.Method + " " + p.Url + "\n")
+	for _, h := range p.Headers {
+		sb.WriteString(h.Name + ": " + h.Value + "\n")
+	}
+
+	switch {
+	case p.BodyType == parser.BodyTypeFormUrlEncoded && len(p.Form) > 0:
+		sb.WriteString("Content-Type: " + parser.BodyTypeFormUrlEncoded + "\n\n")
+		fields := make([]string, 0, len(p.Form))
+		for _, f := range p.Form {
+			fields = append(fields, url.QueryEscape(f.Name)+"="+escape(f.Value, url.QueryEscape))
+		}
+		sb.WriteString(strings.Join(fields, "&") + "\n")
+	case p.BodyType == parser.BodyTypeFormData && len(p.Form) > 0:
+		sb.WriteString("Content-Type: " + parser.BodyTypeFormData + "; boundary=" + boundary + "\n\n")
+		for _, f := range p.Form {
+			sb.WriteString("--" + boundary + "\n")
+			if f.File {
+				sb.WriteString(fmt.Sprintf("Content-Disposition: form-data; name=%q; filename=%q\n\n< ./%s\n", f.Name, f.Name, f.Name))
+			} else {
+				sb.WriteString(fmt.Sprintf("Content-Disposition: form-data; name=%q\n\n%s\n", f.Name, f.Value))
+			}
+		}
+		sb.WriteString("--" + boundary + "--\n")
+	case p.Body != "":
+		sb.WriteString("Content-Type: " + p.BodyType + "\n\n")
+		sb.WriteString(p.Body + "\n")
+	}
+	return sb.String()
+}
+
+// NewEnvFile 生成 JetBrains HTTP Client 的环境文件，host 为请求地址，其他变量的值为空。
+//
+//	VS Code REST Client 可以将同样的变量配置在 rest-client.environmentVariables 中。
+func NewEnvFile(host string, vars []string) ([]byte, error) {
+	env := make(map[string]string, len(vars))
+	for _, name := range vars {
+		env[name] = ""
+	}
+	env[VarHost] = host
+	return json.MarshalIndent(map[string]map[string]string{DefaultEnv: env}, "", "    ")
+}
@@ -0,0 +1,147 @@
+// Package snippet 根据接口文档生成可以直接发送的请求，包括 JetBrains / VS Code 的 .http 文件和 curl 命令。
+//
+// 请求地址使用 {{host}} 变量，示例值中的 {{TOKEN}} 等变量保持不变，由 .http 的环境文件或 shell 环境变量提供。
+package snippet
+
+import (
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/sample"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VarHost 请求地址变量
+const VarHost = "host"
+
+// variableRegexp 匹配示例值中的变量，如：bearer {{TOKEN}}
+var variableRegexp = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+
+// Request 根据接口文档生成的请求
+type Request struct {
+	Name        string        // 接口名称（目录+标题）
+	Title       string        // 接口标题
+	Description string        // 接口说明
+	Method      string        // 请求方式，大写
+	Url         string        // 请求地址，包括 {{host}} 和查询参数
+	Headers     []Field       // 请求头
+	BodyType    string        // 请求体类型
+	Form        []Field       // 表单参数
+	Body        string        // JSON 请求体示例
+	Folder      parser.Folder // 接口目录
+}
+
+// Field 请求头或表单参数
+type Field struct {
+	Name  string
+	Value string
+	File  bool // 文件类型的表单参数
+}
+
+// NewRequest 根据接口文档生成请求，参数使用示例值；选填且没有示例值的查询参数和请求头不会包含在请求中。
+func NewRequest(apiItem *parser.ApiItem) *Request {
+	req := &Request{
+		Name:        apiItem.Name(),
+		Title:       apiItem.Title,
+		Description: apiItem.Description,
+		Method:      strings.ToUpper(apiItem.Method),
+		BodyType:    apiItem.Parameters.BodyType,
+		Folder:      apiItem.Folder,
+	}
+
+	// 没有注释的路径参数按字符串生成示例值
+	path := parser.ReplacePathParams(apiItem.Path, func(name string) string {
+		param := parser.Parameter{Name: name, Type: parser.STRING}
+		for _, p := range apiItem.Parameters.Path {
+			if p.Name == name {
+				param = p
+				break
+			}
+		}
+		return escape(sample.Parameter(param), url.PathEscape)
+	})
+	queries := make([]string, 0, len(apiItem.Parameters.Query))
+	for _, param := range apiItem.Parameters.Query {
+		if included(param) {
+			queries = append(queries, url.QueryEscape(param.Name)+"="+escape(sample.Parameter(param), url.QueryEscape))
+		}
+	}
+	req.Url = "{{" + VarHost + "}}" + path
+	if len(queries) > 0 {
+		req.Url += "?" + strings.Join(queries, "&")
+	}
+
+	for _, param := range apiItem.Parameters.Header {
+		if included(param) {
+			req.Headers = append(req.Headers, Field{Name: param.Name, Value: sample.Parameter(param)})
+		}
+	}
+	if len(apiItem.Parameters.Cookie) > 0 {
+		cookies := make([]string, 0, len(apiItem.Parameters.Cookie))
+		for _, param := range apiItem.Parameters.Cookie {
+			if included(param) {
+				cookies = append(cookies, param.Name+"="+sample.Parameter(param))
+			}
+		}
+		if len(cookies) > 0 {
+			req.Headers = append(req.Headers, Field{Name: "Cookie", Value: strings.Join(cookies, "; ")})
+		}
+	}
+
+	switch req.BodyType {
+	case parser.BodyTypeFormData, parser.BodyTypeFormUrlEncoded:
+		for _, param := range apiItem.Parameters.FormData {
+			req.Form = append(req.Form, Field{Name: param.Name, Value: sample.Parameter(param), File: param.Type == "file"})
+		}
+	case "", parser.BodyTypeNone:
+	default:
+		if apiItem.Parameters.JsonSchema != nil {
+			req.Body = sample.JSON(apiItem.Parameters.JsonSchema)
+		}
+	}
+	return req
+}
+
+// included 必填或有示例值的参数
+func included(param parser.Parameter) bool {
+	return param.Required || param.Example != ""
+}
+
+// escape 转义变量以外的内容
+func escape(s string, escapeFunc func(string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range variableRegexp.FindAllStringIndex(s, -1) {
+		sb.WriteString(escapeFunc(s[last:loc[0]]))
+		sb.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(escapeFunc(s[last:]))
+	return sb.String()
+}
+
+// Variables 请求中用到的变量，按名称排序
+func Variables(reqs []*Request) []string {
+	vars := map[string]bool{VarHost: true}
+	for _, req := range reqs {
+		texts := []string{req.Url, req.Body}
+		for _, f := range req.Headers {
+			texts = append(texts, f.Value)
+		}
+		for _, f := range req.Form {
+			texts = append(texts, f.Value)
+		}
+		for _, text := range texts {
+			for _, match := range variableRegexp.FindAllStringSubmatch(text, -1) {
+				vars[match[1]] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
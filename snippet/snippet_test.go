@@ -0,0 +1,96 @@
+package snippet
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"github.com/whaios/apigo/sample"
+	"testing"
+)
+
+func TestNewRequest(t *testing.T) {
+	getPet := &parser.ApiItem{
+		Title:       "查询宠物详情",
+		Folder:      parser.Folder{"宠物商城", "宠物管理"},
+		Description: "指定id查询宠物详情",
+		Method:      "get",
+		Path:        "/pet/{petId}",
+		Parameters: parser.Parameters{
+			Path:   []parser.Parameter{{Name: "petId", Type: "int", Required: true}},
+			Query:  []parser.Parameter{{Name: "q", Type: "string", Example: "a b"}, {Name: "page", Type: "int"}},
+			Header: []parser.Parameter{{Name: "Authorization", Type: "string", Required: true, Example: "bearer {{TOKEN}}"}},
+		},
+	}
+	addPet := &parser.ApiItem{
+		Title:  "新建宠物信息",
+		Method: "post",
+		Path:   "/pet",
+		Parameters: parser.Parameters{
+			BodyType: parser.BodyTypeFormData,
+			FormData: []parser.Parameter{{Name: "name", Type: "string", Example: "Kitty's"}, {Name: "photo", Type: "file"}},
+		},
+	}
+	login := &parser.ApiItem{
+		Title:  "登录",
+		Method: "post",
+		Path:   "/login",
+		Parameters: parser.Parameters{
+			BodyType:   parser.BodyTypeJSON,
+			JsonSchema: spec.StringProperty(),
+		},
+	}
+
+	Convey("测试生成请求", t, func() {
+		req := NewRequest(getPet)
+		So(req.Method, ShouldEqual, "GET")
+		So(req.Url, ShouldEqual, "{{host}}/pet/1?q=a+b")
+		So(req.Headers, ShouldResemble, []Field{{Name: "Authorization", Value: "bearer {{TOKEN}}"}})
+		So(Variables([]*Request{req}), ShouldResemble, []string{"TOKEN", "host"})
+
+		// 路径中没有注释的参数
+		req = NewRequest(&parser.ApiItem{Title: "上传照片", Method: "post", Path: "/pet/{petId}/photo/{name}",
+			Parameters: parser.Parameters{Path: []parser.Parameter{{Name: "petId", Type: "int", Required: true}}}})
+		So(req.Url, ShouldEqual, "{{host}}/pet/1/photo/"+sample.DefaultString)
+	})
+
+	Convey("测试生成 .http 文件", t, func() {
+		reqs := []*Request{NewRequest(getPet), NewRequest(addPet), NewRequest(login)}
+		files := HttpFiles(reqs, "apigo")
+		So(len(files), ShouldEqual, 2)
+		So(files[0].Path, ShouldEqual, "宠物商城/宠物管理.http")
+		So(files[0].String(), ShouldEqual, "### 查询宠物详情\n# 指定id查询宠物详情\nGET {{host}}/pet/1?q=a+b\nAuthorization: bearer {{TOKEN}}\n")
+		So(files[1].Path, ShouldEqual, "apigo.http")
+		So(files[1].String(), ShouldEqual, `### 新建宠物信息
+POST {{host}}/pet
+Content-Type: multipart/form-data; boundary=apigo-boundary
+
+--apigo-boundary
+Content-Disposition: form-data; name="name"
+
+Kitty's
+--apigo-boundary
+Content-Disposition: form-data; name="photo"; filename="photo"
+
+< ./photo
+--apigo-boundary--
+
+### 登录
+POST {{host}}/login
+Content-Type: application/json
+
+"string"
+`)
+
+		data, err := NewEnvFile("http://localhost:8080", Variables(reqs))
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, `"host": "http://localhost:8080"`)
+		So(string(data), ShouldContainSubstring, `"TOKEN": ""`)
+	})
+
+	Convey("测试生成 curl 命令", t, func() {
+		So(NewRequest(getPet).Curl(), ShouldEqual, "curl -X GET \"${host}/pet/1?q=a+b\" \\\n  -H \"Authorization: bearer ${TOKEN}\"")
+		So(NewRequest(addPet).Curl(), ShouldEqual, "curl -X POST \"${host}/pet\" \\\n  -F 'name=Kitty'\\''s' \\\n  -F 'photo=@photo'")
+		So(NewRequest(login).Curl(), ShouldEqual, "curl -X POST \"${host}/login\" \\\n  -H 'Content-Type: application/json' \\\n  -d '\"string\"'")
+		So(shellWord(`{{user.id}} "$HOME"`), ShouldEqual, `"${user_id} \"\$HOME\""`)
+	})
+}
@@ -0,0 +1,33 @@
+import axios, { AxiosInstance } from "axios";
+
+let client: AxiosInstance = axios.create();
+
+/** 设置使用的 axios 实例，可以在实例中配置 baseURL、公共请求头和拦截器 */
+export function setClient(instance: AxiosInstance): void {
+    client = instance;
+}
+
+function encodeForm(body: unknown, bodyType: string): FormData | URLSearchParams {
+    const form = bodyType === "multipart/form-data" ? new FormData() : new URLSearchParams();
+    for (const [key, value] of Object.entries(body as Record<string, unknown>)) {
+        if (value === undefined || value === null) continue;
+        if (form instanceof FormData && value instanceof Blob) form.append(key, value);
+        else form.append(key, String(value));
+    }
+    return form;
+}
+
+async function request<T>(method: string, path: string, query?: Record<string, unknown>, body?: unknown, bodyType?: string): Promise<T> {
+    let data = body;
+    if (body !== undefined && (bodyType === "multipart/form-data" || bodyType === "application/x-www-form-urlencoded")) {
+        data = encodeForm(body, bodyType);
+    }
+    const res = await client.request<T>({
+        method,
+        url: path,
+        params: query,
+        data,
+        headers: body !== undefined && bodyType ? { "Content-Type": bodyType } : undefined,
+    });
+    return res.data;
+}
@@ -0,0 +1,216 @@
+package typescript
+
+import (
+	"fmt"
+	"github.com/whaios/apigo/parser"
+	"strconv"
+	"strings"
+)
+
+// header 生成文件的开头
+const header = "// Code generated by apigo. DO NOT EDIT.\n"
+
+// runtimeNames 请求客户端（fetch.ts、axios.ts）中申明的名称
+var runtimeNames = []string{
+	"request", "configure", "encodeForm", "setClient", "client", "clientOptions",
+	"ApiError", "ClientOptions", "axios", "AxiosInstance",
+}
+
+// reservedWords JavaScript 和 TypeScript 的保留字，不能作为函数名
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true, "let": true, "static": true, "implements": true, "interface": true,
+	"package": true, "private": true, "protected": true, "public": true, "await": true,
+}
+
+// Generate 生成所有接口用到的类型定义，client 不为空时同时生成请求客户端，每个接口对应一个函数。
+//
+//	函数名使用接口注释所在的 Go 方法名（首字母小写），请求头（如登录凭证）通过客户端的公共配置设置。
+func Generate(items []parser.ApiItem, client string) (string, error) {
+	var runtime string
+	switch client {
+	case ClientNone:
+	case ClientFetch:
+		runtime = fetchRuntime
+	case ClientAxios:
+		runtime = axiosRuntime
+	default:
+		return "", fmt.Errorf("不支持的请求客户端 %q，可选值: %s, %s", client, ClientFetch, ClientAxios)
+	}
+
+	g := NewGenerator()
+	funcs := make([]string, 0, len(items))
+	funcNames := make(map[string]bool)
+	// 生成的函数和类型不能与请求客户端中的名称重名
+	for _, name := range runtimeNames {
+		funcNames[name] = true
+		g.used[name] = true
+	}
+	for i := range items {
+		apiItem := &items[i]
+		if client == ClientNone {
+			// 只生成类型定义时，只需要收集请求体和响应中的类型
+			if apiItem.Parameters.JsonSchema != nil {
+				g.Type(apiItem.Parameters.JsonSchema)
+			}
+			for _, resp := range apiItem.Responses {
+				g.Type(resp.JsonSchema)
+			}
+			continue
+		}
+		funcs = append(funcs, g.function(apiItem, uniqueName(funcNames, functionName(apiItem))))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	if runtime != "" {
+		sb.WriteString("\n" + runtime)
+	}
+	if decls := g.Declarations(); decls != "" {
+		sb.WriteString("\n" + decls)
+	}
+	for _, f := range funcs {
+		sb.WriteString("\n" + f)
+	}
+	return sb.String(), nil
+}
+
+// function 生成接口的请求函数，参数依次为：路径和查询参数 params、请求体 body
+func (g *Generator) function(apiItem *parser.ApiItem, name string) string {
+	args := make([]string, 0, 2)
+	params := append(pathParams(apiItem), apiItem.Parameters.Query...)
+	if len(params) > 0 {
+		optional := "?"
+		for _, p := range params {
+			if p.Required {
+				optional = ""
+			}
+		}
+		args = append(args, "params"+optional+": "+paramsType(params))
+	}
+
+	bodyType, body := "", ""
+	switch {
+	case len(apiItem.Parameters.FormData) > 0:
+		bodyType, body = apiItem.Parameters.BodyType, paramsType(apiItem.Parameters.FormData)
+	case apiItem.Parameters.JsonSchema != nil:
+		bodyType, body = apiItem.Parameters.BodyType, g.Type(apiItem.Parameters.JsonSchema)
+	}
+	if body != "" {
+		args = append(args, "body: "+body)
+	}
+
+	// 返回第一个成功响应的数据
+	result := "unknown"
+	for _, resp := range apiItem.Responses {
+		if resp.Code >= 200 && resp.Code < 300 {
+			if resp.JsonSchema != nil {
+				result = g.Type(resp.JsonSchema)
+			}
+			// 组合类型等匿名类型单独定义，如：GetPetResponse
+			if !identRegexp.MatchString(result) {
+				alias := uniqueName(g.used, pascalCase(name)+"Response")
+				g.decls = append(g.decls, fmt.Sprintf("/** %s 的响应 */\nexport type %s = %s;\n", apiItem.Endpoint(), alias, result))
+				result = alias
+			}
+			break
+		}
+	}
+
+	path := parser.ReplacePathParams(apiItem.Path, func(name string) string {
+		return "${encodeURIComponent(String(" + propertyAccess("params", name) + "))}"
+	})
+	query := "undefined"
+	if len(apiItem.Parameters.Query) > 0 {
+		fields := make([]string, 0, len(apiItem.Parameters.Query))
+		for _, p := range apiItem.Parameters.Query {
+			fields = append(fields, propertyKey(p.Name)+": "+propertyAccess("params?", p.Name))
+		}
+		query = "{ " + strings.Join(fields, ", ") + " }"
+	}
+	callArgs := []string{strconv.Quote(strings.ToUpper(apiItem.Method)), "`" + path + "`", query}
+	if body != "" {
+		callArgs = append(callArgs, "body", strconv.Quote(bodyType))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("/**\n * " + apiItem.Title + "\n")
+	if desc := strings.TrimSpace(apiItem.Description); desc != "" {
+		sb.WriteString(" *\n")
+		for _, line := range strings.Split(desc, "\n") {
+			sb.WriteString(" * " + line + "\n")
+		}
+	}
+	sb.WriteString(" *\n * " + apiItem.Endpoint() + "\n */\n")
+	sb.WriteString(fmt.Sprintf("export function %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), result))
+	sb.WriteString(fmt.Sprintf("    return request<%s>(%s);\n}\n", result, strings.Join(callArgs, ", ")))
+	return sb.String()
+}
+
+// pathParams 路径参数，路径中没有注释的参数作为必填的字符串参数
+func pathParams(apiItem *parser.ApiItem) []parser.Parameter {
+	params := append([]parser.Parameter{}, apiItem.Parameters.Path...)
+	for _, name := range parser.PathParams(apiItem.Path) {
+		exists := false
+		for _, p := range apiItem.Parameters.Path {
+			if p.Name == name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			params = append(params, parser.Parameter{Name: name, Type: parser.STRING, Required: true})
+		}
+	}
+	return params
+}
+
+// paramsType 参数对象的类型，选填的参数使用 ?:
+func paramsType(params []parser.Parameter) string {
+	fields := make([]string, 0, len(params))
+	for _, p := range params {
+		optional := "?"
+		if p.Required {
+			optional = ""
+		}
+		fields = append(fields, propertyKey(p.Name)+optional+": "+paramType(p.Type))
+	}
+	return "{ " + strings.Join(fields, "; ") + " }"
+}
+
+// functionName 使用接口注释所在的 Go 方法名，首字母小写；没有时根据请求方式和路径生成，如：GET /pet/{petId} -> getPetPetId。
+//
+//	与 JavaScript 保留字相同时加上 _，如：delete_
+func functionName(apiItem *parser.ApiItem) string {
+	var name string
+	if apiItem.Source != nil && apiItem.Source.Func != "" {
+		name = strings.ToLower(apiItem.Source.Func[:1]) + apiItem.Source.Func[1:]
+	} else {
+		name = strings.ToLower(apiItem.Method)
+		for _, seg := range strings.Split(apiItem.Path, "/") {
+			name += pascalCase(seg)
+		}
+		if !identRegexp.MatchString(name) {
+			name = strings.ToLower(apiItem.Method) + "Api"
+		}
+	}
+	if reservedWords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// uniqueName 重名时加上序号
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
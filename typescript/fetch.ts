@@ -0,0 +1,60 @@
+export interface ClientOptions {
+    /** 接口地址，如：http://localhost:8080 */
+    baseUrl?: string;
+    /** 公共请求头，如登录凭证；使用函数时每次请求都会重新获取 */
+    headers?: Record<string, string> | (() => Record<string, string>);
+    /** 自定义 fetch 实现 */
+    fetch?: typeof fetch;
+}
+
+let clientOptions: ClientOptions = {};
+
+/** 设置接口地址和公共请求头 */
+export function configure(options: ClientOptions): void {
+    clientOptions = options;
+}
+
+/** 响应状态码不是 2xx 时抛出的错误 */
+export class ApiError extends Error {
+    constructor(public readonly status: number, public readonly body: string) {
+        super(`${status} ${body}`);
+    }
+}
+
+function encodeForm(body: unknown, bodyType: string): FormData | URLSearchParams {
+    const form = bodyType === "multipart/form-data" ? new FormData() : new URLSearchParams();
+    for (const [key, value] of Object.entries(body as Record<string, unknown>)) {
+        if (value === undefined || value === null) continue;
+        if (form instanceof FormData && value instanceof Blob) form.append(key, value);
+        else form.append(key, String(value));
+    }
+    return form;
+}
+
+async function request<T>(method: string, path: string, query?: Record<string, unknown>, body?: unknown, bodyType?: string): Promise<T> {
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(query || {})) {
+        if (value !== undefined && value !== null) search.append(key, String(value));
+    }
+    const qs = search.toString();
+    const headers: Record<string, string> = {
+        ...(typeof clientOptions.headers === "function" ? clientOptions.headers() : clientOptions.headers),
+    };
+    let data: BodyInit | undefined;
+    if (body !== undefined) {
+        if (bodyType === "multipart/form-data" || bodyType === "application/x-www-form-urlencoded") {
+            data = encodeForm(body, bodyType);
+        } else {
+            headers["Content-Type"] = bodyType || "application/json";
+            data = JSON.stringify(body);
+        }
+    }
+    const res = await (clientOptions.fetch || fetch)((clientOptions.baseUrl || "") + path + (qs ? "?" + qs : ""), {
+        method,
+        headers,
+        body: data,
+    });
+    const text = await res.text();
+    if (!res.ok) throw new ApiError(res.status, text);
+    return (text ? JSON.parse(text) : undefined) as T;
+}
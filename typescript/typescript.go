@@ -0,0 +1,229 @@
+// Package typescript 根据解析的 Go 类型生成 TypeScript 类型定义，并可以生成每个接口对应一个函数的请求客户端。
+package typescript
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/go-openapi/spec"
+	"github.com/whaios/apigo/parser"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 请求客户端
+const (
+	ClientNone  = ""      // 只生成类型定义
+	ClientFetch = "fetch" // 使用 fetch
+	ClientAxios = "axios" // 使用 axios
+)
+
+//go:embed fetch.ts
+var fetchRuntime string
+
+//go:embed axios.ts
+var axiosRuntime string
+
+// identRegexp 不需要加引号的属性名
+var identRegexp = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// Generator 收集命名的类型，同一个 Go 类型只生成一次
+type Generator struct {
+	names map[string]string // Go 类型全名 -> TypeScript 类型名
+	used  map[string]bool   // 已使用的 TypeScript 类型名
+	decls []string          // 类型定义，按第一次使用的顺序
+}
+
+// NewGenerator 创建类型生成器
+func NewGenerator() *Generator {
+	return &Generator{names: make(map[string]string), used: make(map[string]bool)}
+}
+
+// Type 获取数据结构对应的 TypeScript 类型，Go 中命名的类型生成为 interface 或 type，匿名的类型直接展开。
+func (g *Generator) Type(schema *spec.Schema) string {
+	return g.typeOf(schema, 0)
+}
+
+func (g *Generator) typeOf(schema *spec.Schema, depth int) string {
+	if schema == nil || depth > parser.SchemaMaxDepth {
+		return "unknown"
+	}
+	fullName := parser.SchemaGetTypeFullName(schema)
+	if fullName == "" {
+		return g.expr(schema, depth)
+	}
+	if name, ok := g.names[fullName]; ok {
+		return name
+	}
+
+	name := g.newName(fullName)
+	g.names[fullName] = name
+	// 先占位，保证类型定义按第一次使用的顺序输出
+	idx := len(g.decls)
+	g.decls = append(g.decls, "")
+	// 命名类型单独定义，缩进从 0 开始；递归引用时已有类型名，不会死循环
+	if parser.SchemaType(schema) == parser.OBJECT && len(schema.AllOf) == 0 && len(schema.Properties) > 0 {
+		g.decls[idx] = fmt.Sprintf("/** %s */\nexport interface %s %s\n", fullName, name, g.object(schema, 0))
+	} else {
+		g.decls[idx] = fmt.Sprintf("/** %s */\nexport type %s = %s;\n", fullName, name, g.expr(schema, 0))
+	}
+	return name
+}
+
+// newName 使用 Go 类型名作为 TypeScript 类型名，重名时加上包名
+func (g *Generator) newName(fullName string) string {
+	pkg, name := "", fullName
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		pkg, name = fullName[:i], fullName[i+1:]
+	}
+	candidates := []string{name}
+	if pkg != "" {
+		pkgName := pkg[strings.LastIndex(pkg, "/")+1:]
+		candidates = append(candidates, pascalCase(pkgName)+name)
+	}
+	for i := 2; ; i++ {
+		for _, c := range candidates {
+			if !g.used[c] {
+				g.used[c] = true
+				return c
+			}
+		}
+		candidates = []string{name + strconv.Itoa(i)}
+	}
+}
+
+// expr 匿名类型的表达式
+func (g *Generator) expr(schema *spec.Schema, depth int) string {
+	if len(schema.AllOf) > 0 {
+		types := make([]string, 0, len(schema.AllOf))
+		for i := range schema.AllOf {
+			types = append(types, g.typeOf(&schema.AllOf[i], depth))
+		}
+		return strings.Join(types, " & ")
+	}
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			values = append(values, literal(v))
+		}
+		return strings.Join(values, " | ")
+	}
+
+	switch parser.SchemaType(schema) {
+	case parser.OBJECT:
+		if len(schema.Properties) == 0 {
+			if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+				return "Record<string, " + g.typeOf(schema.AdditionalProperties.Schema, depth+1) + ">"
+			}
+			return "Record<string, unknown>"
+		}
+		return g.object(schema, depth)
+	case parser.ARRAY:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "unknown[]"
+		}
+		item := g.typeOf(schema.Items.Schema, depth+1)
+		if strings.ContainsAny(item, "&|") && !strings.HasPrefix(item, "{") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case parser.STRING:
+		return "string"
+	case parser.INTEGER, parser.NUMBER:
+		return "number"
+	case parser.BOOLEAN:
+		return "boolean"
+	case "file":
+		return "Blob"
+	}
+	return "unknown"
+}
+
+// object 对象的属性按 Go 结构体中字段的顺序输出，选填的属性使用 ?:
+func (g *Generator) object(schema *spec.Schema, depth int) string {
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	indent := strings.Repeat("    ", depth+1)
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for _, name := range parser.SchemaPropertyNames(schema) {
+		prop := schema.Properties[name]
+		if prop.Description != "" {
+			sb.WriteString(indent + "/** " + prop.Description + " */\n")
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		sb.WriteString(indent + propertyKey(name) + optional + ": " + g.typeOf(&prop, depth+1) + ";\n")
+	}
+	sb.WriteString(strings.Repeat("    ", depth) + "}")
+	return sb.String()
+}
+
+// Declarations 所有命名类型的定义
+func (g *Generator) Declarations() string {
+	return strings.Join(g.decls, "\n")
+}
+
+// paramType 参数的数据类型，参数类型可能是 Go 类型名称，如：int64
+func paramType(tpe string) string {
+	switch tpe {
+	case parser.INTEGER, parser.NUMBER, "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "number"
+	case parser.BOOLEAN, "bool":
+		return "boolean"
+	case "file":
+		return "Blob"
+	case parser.ARRAY:
+		return "string[]"
+	}
+	return "string"
+}
+
+func propertyKey(name string) string {
+	if identRegexp.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// propertyAccess 访问对象的属性，obj 以 ? 结尾时使用可选链，如：params?.lang
+func propertyAccess(obj, name string) string {
+	if identRegexp.MatchString(name) {
+		return obj + "." + name
+	}
+	if strings.HasSuffix(obj, "?") {
+		return obj + ".[" + strconv.Quote(name) + "]"
+	}
+	return obj + "[" + strconv.Quote(name) + "]"
+}
+
+func literal(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// pascalCase 首字母大写，去掉 - 和 _ 并将后面的字母大写，如：pet_store -> PetStore
+func pascalCase(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '-' || r == '_' || r == '.' || r == ' ' || r == '{' || r == '}' {
+			upper = true
+			continue
+		}
+		if upper {
+			sb.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package typescript
+
+import (
+	"github.com/go-openapi/spec"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/whaios/apigo/parser"
+	"strings"
+	"testing"
+)
+
+func TestGenerator_Type(t *testing.T) {
+	Convey("测试生成类型定义", t, func() {
+		tag := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       []string{parser.OBJECT},
+			Properties: map[string]spec.Schema{"name": *spec.StringProperty()},
+		}}, "petshop/model.Tag")
+		otherTag := parser.SchemaWithTypeFullName(spec.StringProperty(), "petshop/other.Tag")
+		pet := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:     []string{parser.OBJECT},
+			Required: []string{"id"},
+			Properties: map[string]spec.Schema{
+				"id":        *spec.Int64Property().WithDescription("宠物ID编号"),
+				"tags":      *spec.ArrayProperty(tag),
+				"kind":      *otherTag,
+				"photo-url": *spec.StringProperty(),
+				"extra":     *spec.MapProperty(spec.BoolProperty()),
+			},
+		}}, "petshop/model.Pet")
+		parser.SchemaSetPropertiesOrders(pet, []string{"id", "tags", "kind"})
+
+		g := NewGenerator()
+		So(g.Type(pet), ShouldEqual, "Pet")
+		So(g.Type(pet), ShouldEqual, "Pet")
+		So(g.Type(spec.ArrayProperty(pet)), ShouldEqual, "Pet[]")
+		So(g.Declarations(), ShouldEqual, `/** petshop/model.Pet */
+export interface Pet {
+    /** 宠物ID编号 */
+    id: number;
+    tags?: Tag[];
+    kind?: OtherTag;
+    extra?: Record<string, boolean>;
+    "photo-url"?: string;
+}
+
+/** petshop/model.Tag */
+export interface Tag {
+    name?: string;
+}
+
+/** petshop/other.Tag */
+export type OtherTag = string;
+`)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	httpCode := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{parser.OBJECT},
+		Properties: map[string]spec.Schema{"errcode": *spec.Int32Property()},
+	}}, "petshop/comm.HttpCode")
+	pet := parser.SchemaWithTypeFullName(&spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{parser.OBJECT},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()},
+	}}, "petshop/model.Pet")
+	items := []parser.ApiItem{
+		{
+			Title:  "查询宠物详情",
+			Method: "get",
+			Path:   "/pet/{petId}",
+			Parameters: parser.Parameters{
+				Path:  []parser.Parameter{{Name: "petId", Type: "int", Required: true}},
+				Query: []parser.Parameter{{Name: "page-size", Type: "int"}},
+			},
+			Responses: []*parser.Response{{Code: 200, JsonSchema: spec.ComposedSchema(*httpCode, spec.Schema{SchemaProps: spec.SchemaProps{
+				Type:       []string{parser.OBJECT},
+				Properties: map[string]spec.Schema{"data": *pet},
+			}})}},
+			Source: &parser.Source{Func: "GetPet"},
+		},
+		{
+			Title:  "删除宠物",
+			Method: "delete",
+			Path:   "/pet/{petId}",
+			Parameters: parser.Parameters{
+				BodyType:   parser.BodyTypeJSON,
+				JsonSchema: pet,
+			},
+			Responses: []*parser.Response{{Code: 200, JsonSchema: httpCode}},
+		},
+	}
+
+	Convey("测试只生成类型定义", t, func() {
+		ts, err := Generate(items, ClientNone)
+		So(err, ShouldBeNil)
+		So(ts, ShouldStartWith, header)
+		So(ts, ShouldContainSubstring, "export interface HttpCode {")
+		So(ts, ShouldContainSubstring, "export interface Pet {")
+		So(ts, ShouldNotContainSubstring, "export function")
+	})
+
+	Convey("测试生成请求客户端", t, func() {
+		ts, err := Generate(items, ClientFetch)
+		So(err, ShouldBeNil)
+		So(ts, ShouldContainSubstring, "export function configure(")
+		So(ts, ShouldContainSubstring, `/** GET /pet/{petId} 的响应 */
+export type GetPetResponse = HttpCode & {
+    data?: Pet;
+};`)
+		So(ts, ShouldContainSubstring, `export function getPet(params: { petId: number; "page-size"?: number }): Promise<GetPetResponse> {
+    return request<GetPetResponse>("GET", `+"`/pet/${encodeURIComponent(String(params.petId))}`"+`, { "page-size": params?.["page-size"] });
+}`)
+		// 路径中没有注释的参数
+		So(ts, ShouldContainSubstring, `export function deletePetPetId(params: { petId: string }, body: Pet): Promise<HttpCode> {`)
+		So(ts, ShouldContainSubstring, `, undefined, body, "application/json");`)
+
+		ts, err = Generate(items, ClientAxios)
+		So(err, ShouldBeNil)
+		So(strings.HasPrefix(strings.TrimPrefix(ts, header), "\nimport axios"), ShouldBeTrue)
+
+		_, err = Generate(items, "jquery")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("测试函数名与保留字、请求客户端中的名称重名", t, func() {
+		names := []parser.ApiItem{
+			{Title: "删除", Method: "delete", Path: "/pet", Source: &parser.Source{Func: "Delete"}},
+			{Title: "请求", Method: "get", Path: "/request", Source: &parser.Source{Func: "Request"}},
+			{Title: "配置", Method: "get", Path: "/configure", Source: &parser.Source{Func: "Configure"}},
+		}
+		ts, err := Generate(names, ClientFetch)
+		So(err, ShouldBeNil)
+		So(ts, ShouldContainSubstring, "export function delete_(")
+		So(ts, ShouldContainSubstring, "export function request2(")
+		So(ts, ShouldContainSubstring, "export function configure2(")
+	})
+}
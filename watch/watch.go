@@ -0,0 +1,110 @@
+// Package watch 以轮询的方式监听目录中 go 代码文件的变化。
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 默认轮询间隔和防抖时间
+const (
+	DefaultInterval = 500 * time.Millisecond
+	DefaultDebounce = 300 * time.Millisecond
+)
+
+// New 创建监听器，dirs 返回要监听的目录（不递归子目录），每次轮询时都会重新获取。
+func New(dirs func() []string) *Watcher {
+	return &Watcher{
+		Interval: DefaultInterval,
+		Debounce: DefaultDebounce,
+		dirs:     dirs,
+	}
+}
+
+// Watcher 文件监听器
+type Watcher struct {
+	Interval time.Duration // 轮询间隔
+	Debounce time.Duration // 防抖时间，最后一次变化后等待该时间没有新的变化，才触发回调
+
+	dirs func() []string
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// Run 开始监听，直到 ctx 结束。
+//
+//	onChange 的参数为发生变化（新增、修改、删除）的文件绝对路径，按字母顺序排序。
+func (w *Watcher) Run(ctx context.Context, onChange func(files []string)) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	snapshot := w.snapshot()
+	pending := make(map[string]bool)
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := w.snapshot()
+		for path, stat := range current {
+			if old, ok := snapshot[path]; !ok || old != stat {
+				pending[path] = true
+				lastChange = time.Now()
+			}
+		}
+		for path := range snapshot {
+			if _, ok := current[path]; !ok {
+				pending[path] = true
+				lastChange = time.Now()
+			}
+		}
+		snapshot = current
+
+		if len(pending) > 0 && time.Since(lastChange) >= w.Debounce {
+			files := make([]string, 0, len(pending))
+			for path := range pending {
+				files = append(files, path)
+			}
+			sort.Strings(files)
+			pending = make(map[string]bool)
+			onChange(files)
+		}
+	}
+}
+
+// snapshot 获取所有监听目录中 go 代码文件的状态
+func (w *Watcher) snapshot() map[string]fileStat {
+	stats := make(map[string]fileStat)
+	for _, dir := range w.dirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			path, err := filepath.Abs(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			stats[path] = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stats
+}
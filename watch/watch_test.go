@@ -0,0 +1,40 @@
+package watch
+
+import (
+	"context"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Run(t *testing.T) {
+	Convey("测试监听文件变化", t, func() {
+		dir := t.TempDir()
+		w := New(func() []string { return []string{dir} })
+		w.Interval = 10 * time.Millisecond
+		w.Debounce = 50 * time.Millisecond
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		changes := make(chan []string, 1)
+		go w.Run(ctx, func(files []string) {
+			changes <- files
+			cancel()
+		})
+
+		time.Sleep(30 * time.Millisecond)
+		// 连续的多次修改只会触发一次回调
+		So(os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "b.go"), []byte("package a"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "a_test.go"), []byte("package a"), 0644), ShouldBeNil)
+
+		select {
+		case files := <-changes:
+			So(files, ShouldResemble, []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")})
+		case <-ctx.Done():
+			So(ctx.Err(), ShouldBeNil)
+		}
+	})
+}